@@ -0,0 +1,53 @@
+package restrum
+
+import (
+	"crypto/subtle"
+	"crypto/x509"
+	"net/http"
+)
+
+// RequireClientCert creates a middleware that rejects requests without a valid client certificate.
+// The leaf certificate is passed to verify, which may be nil to only require that one is present.
+func RequireClientCert(verify func(*x509.Certificate) error) HandlerFunc {
+	return func(ctx *Context) {
+		certs := ctx.ClientCertificates()
+		if len(certs) == 0 {
+			http.Error(ctx.ResponseWriter, "client certificate required", http.StatusForbidden)
+			return
+		}
+
+		if verify != nil {
+			if err := verify(certs[0]); err != nil {
+				http.Error(ctx.ResponseWriter, "invalid client certificate", http.StatusForbidden)
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
+
+// BasicAuth creates a middleware that requires HTTP Basic credentials matching one of the
+// accounts, a map of user names to passwords. On success the user name is stored under
+// the "user" key; otherwise it responds 401 with a WWW-Authenticate challenge.
+func BasicAuth(accounts map[string]string) HandlerFunc {
+	return func(ctx *Context) {
+		user, password, ok := ctx.Request.BasicAuth()
+		if ok {
+			// Compare against every account so the time taken does not reveal which user exists.
+			matched := 0
+			for u, p := range accounts {
+				userOK := subtle.ConstantTimeCompare([]byte(user), []byte(u))
+				passOK := subtle.ConstantTimeCompare([]byte(password), []byte(p))
+				matched |= userOK & passOK
+			}
+			if matched == 1 {
+				ctx.Set("user", user)
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.ResponseWriter.Header().Set("WWW-Authenticate", `Basic realm="Authorization Required", charset="UTF-8"`)
+		http.Error(ctx.ResponseWriter, "UNAUTHORIZED", http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,58 @@
+package restrum
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ShouldBind binds the request body like Bind. It only returns the error and never writes
+// to the response; the ShouldBind methods exist alongside the MustBind ones to make that
+// contract explicit.
+func (ctx *Context) ShouldBind(obj interface{}) error {
+	return ctx.Bind(obj)
+}
+
+// ShouldBindJSON binds the JSON request body like BindJSON without writing to the response.
+func (ctx *Context) ShouldBindJSON(obj interface{}) error {
+	return ctx.BindJSON(obj)
+}
+
+// ShouldBindXML binds the XML request body like BindXML without writing to the response.
+func (ctx *Context) ShouldBindXML(obj interface{}) error {
+	return ctx.BindXML(obj)
+}
+
+// MustBind binds the request body like Bind. On failure it responds 400, or 415 for an
+// unsupported Content-Type and 413 for a body over the size limit, aborts the chain, and
+// returns the error so the handler can return.
+func (ctx *Context) MustBind(obj interface{}) error {
+	return ctx.abortOnBindError(ctx.Bind(obj))
+}
+
+// MustBindJSON binds the JSON request body like BindJSON, responding as MustBind on failure.
+func (ctx *Context) MustBindJSON(obj interface{}) error {
+	return ctx.abortOnBindError(ctx.BindJSON(obj))
+}
+
+// MustBindXML binds the XML request body like BindXML, responding as MustBind on failure.
+func (ctx *Context) MustBindXML(obj interface{}) error {
+	return ctx.abortOnBindError(ctx.BindXML(obj))
+}
+
+// abortOnBindError answers a binding error with a matching status and aborts the chain.
+func (ctx *Context) abortOnBindError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	code := http.StatusBadRequest
+	var maxBytes *http.MaxBytesError
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		code = http.StatusUnsupportedMediaType
+	} else if errors.As(err, &maxBytes) {
+		code = http.StatusRequestEntityTooLarge
+	}
+	http.Error(ctx.ResponseWriter, err.Error(), code)
+	ctx.Abort()
+	return err
+}
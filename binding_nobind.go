@@ -0,0 +1,8 @@
+//go:build restrum_nobind
+
+package restrum
+
+// bindForm reports form bodies as unsupported, since BindForm is left out of this build.
+func (ctx *Context) bindForm(obj interface{}) error {
+	return ErrUnsupportedMediaType
+}
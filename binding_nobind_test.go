@@ -0,0 +1,21 @@
+//go:build restrum_nobind
+
+package restrum
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBindFormUnsupportedWithoutBinders(t *testing.T) {
+	var err error
+	e := New()
+	e.POST("/", func(ctx *Context) { err = ctx.Bind(&bindTarget{}) })
+
+	perform(e, http.MethodPost, "/", strings.NewReader("name=ann"), "Content-Type", "application/x-www-form-urlencoded")
+	if !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Bind returned %v, want %v", err, ErrUnsupportedMediaType)
+	}
+}
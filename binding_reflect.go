@@ -0,0 +1,240 @@
+//go:build !restrum_nobind
+
+// The reflection-based binders live behind the restrum_nobind build tag so that a build
+// using only Bind and the JSON/XML helpers can leave them out; run go build with
+// -tags restrum_nobind to do so. The default build includes them.
+
+package restrum
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// errBindTarget is returned when a binder is given something other than a pointer to a struct.
+var errBindTarget = errors.New("bind target must be a non-nil pointer to a struct")
+
+// BindQuery binds the query parameters onto the fields of obj tagged with `query:"name"`.
+// Repeated parameters fill slice fields.
+func (ctx *Context) BindQuery(obj interface{}) error {
+	return bindValues(obj, "query", ctx.queryValues())
+}
+
+// BindURI binds the matched route parameters onto the fields of obj tagged with
+// `uri:"name"`, converting them to the field types.
+func (ctx *Context) BindURI(obj interface{}) error {
+	values := make(map[string][]string, len(ctx.params))
+	for _, p := range ctx.params {
+		values[p.Key] = append(values[p.Key], p.Value)
+	}
+	return bindValues(obj, "uri", values)
+}
+
+// BindForm binds urlencoded or multipart form values, including the query string, onto the
+// fields of obj tagged with `form:"name"`. Uploaded files bind to fields of type
+// *multipart.FileHeader or []*multipart.FileHeader.
+func (ctx *Context) BindForm(obj interface{}) error {
+	if err := ctx.parseForm(); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+	if err := bindValues(obj, "form", ctx.Request.Form); err != nil {
+		return err
+	}
+	if ctx.Request.MultipartForm != nil {
+		return bindFiles(obj, ctx.Request.MultipartForm.File)
+	}
+	return nil
+}
+
+// bindForm lets Bind decode form bodies with BindForm.
+func (ctx *Context) bindForm(obj interface{}) error {
+	return ctx.BindForm(obj)
+}
+
+// ShouldBindQuery binds the query parameters like BindQuery without writing to the response.
+func (ctx *Context) ShouldBindQuery(obj interface{}) error {
+	return ctx.BindQuery(obj)
+}
+
+// ShouldBindURI binds the route parameters like BindURI without writing to the response.
+func (ctx *Context) ShouldBindURI(obj interface{}) error {
+	return ctx.BindURI(obj)
+}
+
+// ShouldBindForm binds the form values like BindForm without writing to the response.
+func (ctx *Context) ShouldBindForm(obj interface{}) error {
+	return ctx.BindForm(obj)
+}
+
+// MustBindQuery binds the query parameters like BindQuery, responding as MustBind on failure.
+func (ctx *Context) MustBindQuery(obj interface{}) error {
+	return ctx.abortOnBindError(ctx.BindQuery(obj))
+}
+
+// MustBindURI binds the route parameters like BindURI, responding as MustBind on failure.
+func (ctx *Context) MustBindURI(obj interface{}) error {
+	return ctx.abortOnBindError(ctx.BindURI(obj))
+}
+
+// MustBindForm binds the form values like BindForm, responding as MustBind on failure.
+func (ctx *Context) MustBindForm(obj interface{}) error {
+	return ctx.abortOnBindError(ctx.BindForm(obj))
+}
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// bindValues sets the struct fields of obj tagged with tag from the matching values.
+func bindValues(obj interface{}, tag string, values map[string][]string) error {
+	return walkFields(obj, tag, func(name string, fv reflect.Value) error {
+		if fv.Type() == fileHeaderType || fv.Type() == fileHeaderSliceType {
+			return nil
+		}
+
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			return nil
+		}
+		if err := setField(fv, vals, tag); err != nil {
+			return fmt.Errorf("bind %s %q: %w", tag, name, err)
+		}
+		return nil
+	})
+}
+
+// bindFiles sets the file fields of obj tagged with form from the uploaded files.
+func bindFiles(obj interface{}, files map[string][]*multipart.FileHeader) error {
+	return walkFields(obj, "form", func(name string, fv reflect.Value) error {
+		headers := files[name]
+		if len(headers) == 0 {
+			return nil
+		}
+
+		switch fv.Type() {
+		case fileHeaderType:
+			fv.Set(reflect.ValueOf(headers[0]))
+		case fileHeaderSliceType:
+			fv.Set(reflect.ValueOf(headers))
+		}
+		return nil
+	})
+}
+
+// walkFields calls fn for each exported field of the struct obj points to that carries tag,
+// descending into untagged embedded structs.
+func walkFields(obj interface{}, tag string, fn func(name string, fv reflect.Value) error) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errBindTarget
+	}
+	return walkStruct(rv.Elem(), tag, fn)
+}
+
+// walkStruct implements walkFields for the struct value v.
+func walkStruct(v reflect.Value, tag string, fn func(name string, fv reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+
+		name, tagged := field.Tag.Lookup(tag)
+		if field.Anonymous && !tagged && field.Type.Kind() == reflect.Struct {
+			if err := walkStruct(fv, tag, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if !tagged || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if err := fn(name, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setField converts vals to the type of fv and stores the result. tag is the binding source
+// the values came from.
+func setField(fv reflect.Value, vals []string, tag string) error {
+	switch fv.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setValue(slice.Index(i), val, tag); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	case reflect.Pointer:
+		elem := reflect.New(fv.Type().Elem())
+		if err := setValue(elem.Elem(), vals[0], tag); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	default:
+		return setValue(fv, vals[0], tag)
+	}
+}
+
+// setValue parses val into the scalar value v. Bools bound from a form also accept the
+// on/off and yes/no values browsers and checkbox widgets submit.
+func setValue(v reflect.Value, val, tag string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
+	case reflect.Bool:
+		parse := strconv.ParseBool
+		if tag == "form" {
+			parse = parseFormBool
+		}
+		b, err := parse(val)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// parseFormBool parses a form checkbox value: on, yes, 1 and true are true, and off, no, 0,
+// false and the empty string are false, ignoring case.
+func parseFormBool(val string) (bool, error) {
+	switch strings.ToLower(val) {
+	case "on", "yes", "1", "true":
+		return true, nil
+	case "off", "no", "0", "false", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", val)
+}
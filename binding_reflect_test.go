@@ -0,0 +1,93 @@
+//go:build !restrum_nobind
+
+package restrum
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBindFormCheckboxBools(t *testing.T) {
+	type signup struct {
+		Agree     bool `form:"agree"`
+		Subscribe bool `form:"subscribe"`
+		Remember  bool `form:"remember"`
+		Tracking  bool `form:"tracking"`
+	}
+
+	tests := []struct {
+		body string
+		want signup
+	}{
+		{"agree=on&subscribe=YES&remember=1&tracking=True", signup{true, true, true, true}},
+		{"agree=off&subscribe=0&remember=false", signup{}},
+		{"", signup{}},
+	}
+	for _, tt := range tests {
+		var got signup
+		var err error
+		e := New()
+		e.POST("/", func(ctx *Context) { err = ctx.BindForm(&got) })
+
+		perform(e, http.MethodPost, "/", strings.NewReader(tt.body), "Content-Type", "application/x-www-form-urlencoded")
+		if err != nil {
+			t.Errorf("%q: BindForm returned %v", tt.body, err)
+		}
+		if got != tt.want {
+			t.Errorf("%q: bound %+v, want %+v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestBindQueryKeepsStrictBools(t *testing.T) {
+	var err error
+	e := New()
+	e.GET("/", func(ctx *Context) {
+		var q struct {
+			Active bool `query:"active"`
+		}
+		err = ctx.BindQuery(&q)
+	})
+
+	perform(e, http.MethodGet, "/?active=on", nil)
+	if err == nil {
+		t.Error("BindQuery accepted active=on")
+	}
+}
+
+func TestBindDispatchesForms(t *testing.T) {
+	var got bindTarget
+	var err error
+	e := New()
+	e.POST("/", func(ctx *Context) { err = ctx.Bind(&got) })
+
+	perform(e, http.MethodPost, "/", strings.NewReader("name=ann&age=30"), "Content-Type", "application/x-www-form-urlencoded")
+	if err != nil || got != (bindTarget{Name: "ann", Age: 30}) {
+		t.Errorf("Bind = %+v, %v, want ann 30", got, err)
+	}
+}
+
+func TestBindQueryEmbeddedStruct(t *testing.T) {
+	type Pagination struct {
+		Page int `query:"page"`
+		Size int `query:"size"`
+	}
+	type listUsers struct {
+		Pagination
+		Name string `query:"name"`
+	}
+
+	var got listUsers
+	var err error
+	e := New()
+	e.GET("/users", func(ctx *Context) { err = ctx.BindQuery(&got) })
+
+	perform(e, http.MethodGet, "/users?page=3&size=20&name=ann", nil)
+	if err != nil {
+		t.Fatalf("BindQuery returned %v", err)
+	}
+	if want := (listUsers{Pagination: Pagination{Page: 3, Size: 20}, Name: "ann"}); got != want {
+		t.Errorf("bound %+v, want %+v", got, want)
+	}
+}
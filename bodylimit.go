@@ -0,0 +1,25 @@
+package restrum
+
+import "net/http"
+
+// BodyLimit creates a middleware that limits request bodies to maxBytes. Requests that
+// declare a larger Content-Length get a 413 at once; otherwise reads past the limit, such
+// as those made by Bind, fail with an *http.MaxBytesError.
+func BodyLimit(maxBytes int64) HandlerFunc {
+	return func(ctx *Context) {
+		if limitBody(ctx, maxBytes) {
+			ctx.Next()
+		}
+	}
+}
+
+// limitBody wraps the request body in an http.MaxBytesReader, or responds 413 and reports
+// false when the declared Content-Length already exceeds maxBytes.
+func limitBody(ctx *Context, maxBytes int64) bool {
+	if ctx.Request.ContentLength > maxBytes {
+		http.Error(ctx.ResponseWriter, "REQUEST ENTITY TOO LARGE", http.StatusRequestEntityTooLarge)
+		return false
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.ResponseWriter, ctx.Request.Body, maxBytes)
+	return true
+}
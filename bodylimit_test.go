@@ -0,0 +1,43 @@
+package restrum
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimitDeclaredLength(t *testing.T) {
+	called := false
+	e := New()
+	e.Use(BodyLimit(8))
+	e.POST("/", func(ctx *Context) { called = true })
+
+	w := perform(e, http.MethodPost, "/", strings.NewReader(`{"name":"too long"}`))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+	if called {
+		t.Error("handler ran for a body over the limit")
+	}
+}
+
+func TestBodyLimitChunkedBody(t *testing.T) {
+	e := New()
+	e.Use(BodyLimit(8))
+	e.POST("/", func(ctx *Context) {
+		var v map[string]string
+		if ctx.MustBind(&v) != nil {
+			return
+		}
+		ctx.NoContent()
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"too long"}`))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
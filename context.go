@@ -1,70 +1,435 @@
 package restrum
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
 	"net"
 	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const (
+	// maxFormBodySize mirrors the limit net/http applies to urlencoded bodies.
+	maxFormBodySize = 10 << 20
+	// defaultMultipartMemory is the amount of a multipart body kept in memory before spilling
+	// to disk when Config.MaxMultipartMemory is unset.
+	defaultMultipartMemory = 32 << 20
+)
+
+var (
+	// ErrUnsupportedMediaType is returned by Bind when it cannot decode the request's Content-Type.
+	// Handlers can answer it with http.StatusUnsupportedMediaType.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrJSONTooDeep is returned by Bind when the request body exceeds Config.MaxJSONDepth.
+	ErrJSONTooDeep = errors.New("json body exceeds maximum nesting depth")
+	// ErrJSONTooLarge is returned by Bind when an object or array exceeds Config.MaxJSONElements.
+	ErrJSONTooLarge = errors.New("json body exceeds maximum number of elements")
+	// ErrInvalidSignature is returned by GetSignedCookie when a cookie has been tampered with.
+	ErrInvalidSignature = errors.New("invalid cookie signature")
+)
+
+// Param is a single URL parameter captured from the matched route.
+type Param struct {
+	Key   string
+	Value string
+}
+
 // Context represents the context of the current HTTP request.
 type Context struct {
 	Request        *http.Request
 	ResponseWriter http.ResponseWriter
-	Params         map[string]string
 	HTTPMethod     string
 	RoutePath      string
 	ResponseCode   int
+	StartTime      time.Time
 
+	params     []Param
 	current    int
 	config     *Config
+	engine     *Engine
 	middleware []HandlerFunc
+	deferred   []func()
+	keys       map[string]interface{}
+	query      url.Values
+	writer     *responseWriter
+	aborted    bool
+	logger     *log.Logger
+	errs       []error
+	pattern    string
+	timeout    *timeoutScope
+	phases     []phase
 }
 
 // newContext creates a new Context instance.
-func newContext(w http.ResponseWriter, r *http.Request, config *Config) *Context {
-	return &Context{
-		Request:        r,
-		ResponseWriter: w,
-		HTTPMethod:     r.Method,
-		RoutePath:      r.URL.Path,
+func newContext(w http.ResponseWriter, r *http.Request, engine *Engine) *Context {
+	ctx := &Context{
+		Request:    r,
+		HTTPMethod: r.Method,
+		RoutePath:  r.URL.Path,
+		StartTime:  time.Now(),
 
 		current: -1,
-		config:  config,
+		config:  &engine.config,
+		engine:  engine,
 	}
+	ctx.writer = &responseWriter{ResponseWriter: w, ctx: ctx, reportPhases: true}
+	ctx.ResponseWriter = ctx.writer
+	return ctx
 }
 
-// Next executes the next middleware in the chain.
+// Next executes the next middleware in the chain. It does nothing once Abort has been called.
 func (ctx *Context) Next() {
+	if ctx.aborted {
+		return
+	}
 	ctx.current++
 	if ctx.current < len(ctx.middleware) {
+		if ctx.config.Debug {
+			ctx.timePhase(ctx.middleware[ctx.current])
+			return
+		}
 		ctx.middleware[ctx.current](ctx)
 	}
 }
 
+// Abort stops the chain: later calls to Next run no further middleware or handlers.
+// Middleware already running continues after its own call to Next returns.
+func (ctx *Context) Abort() {
+	ctx.aborted = true
+}
+
+// AbortWithStatus sends the header with the given status code and stops the chain.
+func (ctx *Context) AbortWithStatus(code int) {
+	ctx.Status(code)
+	ctx.Abort()
+}
+
+// IsAborted reports whether Abort has been called.
+func (ctx *Context) IsAborted() bool {
+	return ctx.aborted
+}
+
+// Since returns the time elapsed since the request started.
+func (ctx *Context) Since() time.Duration {
+	return time.Since(ctx.StartTime)
+}
+
+// Wait blocks until a value arrives on ch, the client disconnects, or timeout elapses,
+// whichever comes first. It reports whether a value was received. A timeout of zero or
+// less waits without a time limit. A closed channel yields the zero value and false.
+func (ctx *Context) Wait(ch <-chan any, timeout time.Duration) (any, bool) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case v, ok := <-ch:
+		return v, ok
+	case <-ctx.Request.Context().Done():
+		return nil, false
+	case <-expired:
+		return nil, false
+	}
+}
+
+// Defer registers fn to run after the request chain completes, even if it was cut short.
+// Deferred functions run in last-in, first-out order.
+func (ctx *Context) Defer(fn func()) {
+	ctx.deferred = append(ctx.deferred, fn)
+}
+
+// runDeferred executes the functions registered with Defer in reverse order.
+func (ctx *Context) runDeferred() {
+	for i := len(ctx.deferred) - 1; i >= 0; i-- {
+		ctx.deferred[i]()
+	}
+	ctx.deferred = nil
+}
+
+// Set stores a value on the context for later middleware and handlers.
+func (ctx *Context) Set(key string, value interface{}) {
+	if ctx.keys == nil {
+		ctx.keys = make(map[string]interface{})
+	}
+	ctx.keys[key] = value
+}
+
+// Get returns the value stored under key and whether it exists.
+func (ctx *Context) Get(key string) (interface{}, bool) {
+	value, ok := ctx.keys[key]
+	return value, ok
+}
+
+// GetString returns the value stored under key as a string, or "" if it is absent or not a string.
+func (ctx *Context) GetString(key string) string {
+	value, _ := ctx.keys[key].(string)
+	return value
+}
+
+// GetInt returns the value stored under key as an int, or 0 if it is absent or not an int.
+func (ctx *Context) GetInt(key string) int {
+	value, _ := ctx.keys[key].(int)
+	return value
+}
+
+// GetBool returns the value stored under key as a bool, or false if it is absent or not a bool.
+func (ctx *Context) GetBool(key string) bool {
+	value, _ := ctx.keys[key].(bool)
+	return value
+}
+
+// Set stores v on ctx under key, like Context.Set, fixing its type for a matching Get.
+func Set[T any](ctx *Context, key string, v T) {
+	ctx.Set(key, v)
+}
+
+// Get returns the value stored on ctx under key as a T. It reports false, with the zero
+// T, if the key is absent or holds a value of another type.
+func Get[T any](ctx *Context, key string) (T, bool) {
+	value, ok := ctx.keys[key].(T)
+	return value, ok
+}
+
 // FormValue returns the form value associated with the given key.
+// The body is parsed according to its content type regardless of the request method.
 func (ctx *Context) FormValue(key string) string {
+	_ = ctx.parseForm()
 	return ctx.Request.FormValue(key)
 }
 
+// parseForm parses the query string and the urlencoded or multipart request body.
+// Unlike net/http, urlencoded bodies are read for every method, not only POST, PUT and PATCH.
+func (ctx *Context) parseForm() error {
+	r := ctx.Request
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if r.PostForm == nil && r.Body != nil && contentType == "application/x-www-form-urlencoded" {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxFormBodySize+1))
+			if err != nil {
+				return err
+			}
+			if len(body) > maxFormBodySize {
+				return errors.New("http: form body too large")
+			}
+			values, err := url.ParseQuery(string(body))
+			if err != nil {
+				return err
+			}
+			r.PostForm = values
+		}
+	}
+
+	if contentType == "multipart/form-data" {
+		return r.ParseMultipartForm(ctx.maxMultipartMemory())
+	}
+	return r.ParseForm()
+}
+
+// maxMultipartMemory returns Config.MaxMultipartMemory, or the default when it is unset.
+func (ctx *Context) maxMultipartMemory() int64 {
+	if ctx.config.MaxMultipartMemory > 0 {
+		return ctx.config.MaxMultipartMemory
+	}
+	return defaultMultipartMemory
+}
+
+// FormFile returns the first uploaded file for the given multipart form field.
+func (ctx *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	if ctx.Request.MultipartForm == nil {
+		if err := ctx.Request.ParseMultipartForm(ctx.maxMultipartMemory()); err != nil {
+			return nil, err
+		}
+	}
+
+	file, header, err := ctx.Request.FormFile(name)
+	if err != nil {
+		return nil, err
+	}
+	_ = file.Close()
+	return header, nil
+}
+
+// SaveUploadedFile copies an uploaded file to dst, creating its parent directories.
+func (ctx *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, src)
+	return err
+}
+
 // Param returns the URL parameter associated with the given key.
 func (ctx *Context) Param(key string) string {
-	return ctx.Params[key]
+	for _, p := range ctx.params {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// ParamInt returns the URL parameter associated with the given key parsed as an int.
+func (ctx *Context) ParamInt(key string) (int, error) {
+	return strconv.Atoi(ctx.Param(key))
+}
+
+// RoutePattern returns the pattern of the matched route, e.g., /users/:id, or an empty
+// string when no route matched.
+func (ctx *Context) RoutePattern() string {
+	return ctx.pattern
+}
+
+// Params returns a copy of the matched URL parameters in the order they appear in the route pattern.
+func (ctx *Context) Params() []Param {
+	params := make([]Param, len(ctx.params))
+	copy(params, ctx.params)
+	return params
 }
 
 // QueryParam returns the query parameter associated with the given key.
 func (ctx *Context) QueryParam(key string) string {
-	return ctx.Request.URL.Query().Get(key)
+	return ctx.queryValues().Get(key)
+}
+
+// QueryDefault returns the query parameter associated with the given key, or def if the
+// key is absent. A key present with an empty value returns the empty string.
+func (ctx *Context) QueryDefault(key, def string) string {
+	if values, ok := ctx.queryValues()[key]; ok && len(values) > 0 {
+		return values[0]
+	}
+	return def
+}
+
+// QueryInt returns the query parameter associated with the given key converted to an int.
+func (ctx *Context) QueryInt(key string) (int, error) {
+	return strconv.Atoi(ctx.QueryParam(key))
+}
+
+// QueryArray returns every value of a repeated query parameter, or nil if it is absent.
+func (ctx *Context) QueryArray(key string) []string {
+	return ctx.queryValues()[key]
+}
+
+// queryValues parses the query string on first use and caches it for the request.
+func (ctx *Context) queryValues() url.Values {
+	if ctx.query == nil {
+		ctx.query = ctx.Request.URL.Query()
+	}
+	return ctx.query
+}
+
+// SetHeader sets a response header, replacing any values it already has. An empty value
+// deletes the header.
+func (ctx *Context) SetHeader(key, value string) {
+	if value == "" {
+		ctx.ResponseWriter.Header().Del(key)
+		return
+	}
+	ctx.ResponseWriter.Header().Set(key, value)
+}
+
+// RequestHeader returns the first value of the given request header.
+func (ctx *Context) RequestHeader(key string) string {
+	return ctx.Request.Header.Get(key)
+}
+
+// RequestHeaders returns all request headers. The returned header belongs to the request
+// and must not be modified.
+func (ctx *Context) RequestHeaders() http.Header {
+	return ctx.Request.Header
+}
+
+// Status sends the response header with the given status code and records it in
+// ResponseCode. Only the first call writes the header; later calls with a different code
+// log a warning.
+func (ctx *Context) Status(code int) {
+	if ctx.Written() {
+		if code != ctx.ResponseCode {
+			log.Printf("restrum: %s %s: status %d ignored, %d already sent", ctx.HTTPMethod, ctx.RoutePath, code, ctx.ResponseCode)
+		}
+		return
+	}
+	ctx.ResponseCode = code
+	ctx.ResponseWriter.WriteHeader(code)
+}
+
+// Written reports whether the response header has been sent.
+func (ctx *Context) Written() bool {
+	return ctx.writer.wroteHeader
+}
+
+// Size returns the number of body bytes written to the client so far.
+func (ctx *Context) Size() int {
+	return ctx.writer.size
+}
+
+// ClientGone reports whether a write to the response failed because the client
+// disconnected, as IsClientGone classifies it.
+func (ctx *Context) ClientGone() bool {
+	return ctx.writer.clientGone
+}
+
+// EarlyHints sends a 103 Early Hints response carrying links as Link headers, e.g.,
+// "</app.css>; rel=preload; as=style", so the client can start fetching them before the
+// final response. The links stay set for the final response. It does nothing once the
+// header has been written or for HTTP/1.0 clients, which do not understand 1xx responses.
+func (ctx *Context) EarlyHints(links []string) {
+	if len(links) == 0 || ctx.Written() || !ctx.Request.ProtoAtLeast(1, 1) {
+		return
+	}
+	header := ctx.ResponseWriter.Header()
+	for _, link := range links {
+		header.Add("Link", link)
+	}
+	ctx.ResponseWriter.WriteHeader(http.StatusEarlyHints)
+}
+
+// NoContent sends a 204 No Content response without a body.
+func (ctx *Context) NoContent() {
+	ctx.Status(http.StatusNoContent)
 }
 
 // String sends a plain text response with the given status code and format.
 func (ctx *Context) String(code int, format string) {
 	ctx.ResponseWriter.Header().Set("Content-Type", "text/plain")
-	ctx.ResponseCode = code
-	ctx.ResponseWriter.WriteHeader(code)
+	ctx.Status(code)
 
 	_, err := ctx.ResponseWriter.Write([]byte(format))
 	if err != nil {
@@ -73,21 +438,163 @@ func (ctx *Context) String(code int, format string) {
 }
 
 // JSON sends a JSON response with the given status code and object.
+// Nothing is written if the client has already gone away, and the object is encoded
+// before the header is sent so an encoding failure can still produce a 500.
 func (ctx *Context) JSON(code int, object interface{}) {
+	ctx.renderJSON(code, object, false)
+}
+
+// IndentedJSON sends a JSON response like JSON, indented with two spaces for readability.
+func (ctx *Context) IndentedJSON(code int, object interface{}) {
+	ctx.renderJSON(code, object, true)
+}
+
+// renderJSON encodes object, indented if indent is set, and sends it as JSON.
+func (ctx *Context) renderJSON(code int, object interface{}, indent bool) {
+	if ctx.Request.Context().Err() != nil {
+		return
+	}
+	if ctx.isNotModified() {
+		ctx.NotModified()
+		return
+	}
+
+	var data []byte
+	var err error
+	if indent {
+		data, err = json.MarshalIndent(object, "", "  ")
+	} else {
+		data, err = json.Marshal(object)
+	}
+	if err != nil {
+		http.Error(ctx.ResponseWriter, err.Error(), 500)
+		return
+	}
+
 	ctx.ResponseWriter.Header().Set("Content-Type", "application/json")
-	ctx.ResponseCode = code
-	ctx.ResponseWriter.WriteHeader(code)
+	ctx.Status(code)
+
+	_, err = ctx.ResponseWriter.Write(append(data, '\n'))
+	if err != nil {
+		return
+	}
+}
 
-	encode := json.NewEncoder(ctx.ResponseWriter)
-	if err := encode.Encode(object); err != nil {
+// jsonpCallback matches the callback names JSONP accepts: dotted JavaScript identifiers.
+var jsonpCallback = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// JSONP sends object as JSON wrapped in a call to callback with the given status code.
+// A callback that is not a plain, optionally dotted, identifier gets a 400 so it cannot
+// inject script into the response.
+func (ctx *Context) JSONP(code int, callback string, object interface{}) {
+	if !jsonpCallback.MatchString(callback) {
+		http.Error(ctx.ResponseWriter, "invalid callback", http.StatusBadRequest)
+		return
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		http.Error(ctx.ResponseWriter, err.Error(), 500)
+		return
+	}
+
+	ctx.ResponseWriter.Header().Set("Content-Type", "application/javascript")
+	ctx.ResponseWriter.Header().Set("X-Content-Type-Options", "nosniff")
+	ctx.Status(code)
+
+	_, err = ctx.ResponseWriter.Write([]byte(callback + "(" + string(data) + ");"))
+	if err != nil {
+		return
+	}
+}
+
+// XML sends an XML response with the given status code and object.
+func (ctx *Context) XML(code int, object interface{}) {
+	data, err := xml.Marshal(object)
+	if err != nil {
 		http.Error(ctx.ResponseWriter, err.Error(), 500)
+		return
+	}
+
+	ctx.ResponseWriter.Header().Set("Content-Type", "application/xml")
+	ctx.Status(code)
+
+	_, err = ctx.ResponseWriter.Write(data)
+	if err != nil {
+		return
+	}
+}
+
+// SetETag sets the ETag response header, quoting tag if needed. Once set, JSON answers
+// a GET or HEAD request with 304 Not Modified when If-None-Match matches it.
+func (ctx *Context) SetETag(tag string) {
+	if !strings.HasPrefix(tag, "\"") && !strings.HasPrefix(tag, "W/\"") {
+		tag = "\"" + tag + "\""
+	}
+	ctx.ResponseWriter.Header().Set("ETag", tag)
+}
+
+// SetLastModified sets the Last-Modified response header. Once set, JSON answers a GET or
+// HEAD request with 304 Not Modified when If-Modified-Since is not older than t.
+func (ctx *Context) SetLastModified(t time.Time) {
+	ctx.ResponseWriter.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// NotModified sends a bodiless 304 response, keeping validator headers such as ETag.
+func (ctx *Context) NotModified() {
+	header := ctx.ResponseWriter.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	ctx.Status(http.StatusNotModified)
+}
+
+// isNotModified reports whether the request's conditional headers match the validators
+// already set on the response.
+func (ctx *Context) isNotModified() bool {
+	if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+		return false
+	}
+	header := ctx.ResponseWriter.Header()
+
+	if inm := ctx.Request.Header.Get("If-None-Match"); inm != "" {
+		etag := header.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims, err := http.ParseTime(ctx.Request.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !modified.After(ims)
+}
+
+// JSONBlob sends pre-encoded JSON bytes with the given status code without re-encoding them.
+func (ctx *Context) JSONBlob(code int, data []byte) {
+	ctx.ResponseWriter.Header().Set("Content-Type", "application/json")
+	ctx.Status(code)
+
+	_, err := ctx.ResponseWriter.Write(data)
+	if err != nil {
+		return
 	}
 }
 
 // Data sends a binary data response with the given status code.
 func (ctx *Context) Data(code int, data []byte) {
-	ctx.ResponseCode = code
-	ctx.ResponseWriter.WriteHeader(code)
+	ctx.Status(code)
 
 	_, err := ctx.ResponseWriter.Write(data)
 	if err != nil {
@@ -98,8 +605,7 @@ func (ctx *Context) Data(code int, data []byte) {
 // HTML sends an HTML response with the given status code and HTML content.
 func (ctx *Context) HTML(code int, html string) {
 	ctx.ResponseWriter.Header().Set("Content-Type", "text/html")
-	ctx.ResponseCode = code
-	ctx.ResponseWriter.WriteHeader(code)
+	ctx.Status(code)
 
 	_, err := ctx.ResponseWriter.Write([]byte(html))
 	if err != nil {
@@ -107,36 +613,328 @@ func (ctx *Context) HTML(code int, html string) {
 	}
 }
 
+// Redirect sends a redirect to location with the given status code.
+// It panics if code is not one of 301, 302, 303, 307 or 308.
+func (ctx *Context) Redirect(code int, location string) {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		panic(fmt.Sprintf("restrum: cannot redirect with status code %d", code))
+	}
+
+	http.Redirect(ctx.ResponseWriter, ctx.Request, location, code)
+}
+
+// File serves the file at path with a content type based on its extension or contents.
+// A missing file results in a 404.
+func (ctx *Context) File(path string) {
+	if _, err := os.Stat(path); err != nil {
+		http.Error(ctx.ResponseWriter, "NOT FOUND", http.StatusNotFound)
+		return
+	}
+
+	http.ServeFile(ctx.ResponseWriter, ctx.Request, path)
+}
+
+// Attachment serves the file at path as a download named filename.
+func (ctx *Context) Attachment(path, filename string) {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+	ctx.ResponseWriter.Header().Set("Content-Disposition", disposition)
+	ctx.File(path)
+}
+
+// Stream writes the header, then calls step repeatedly until it returns false or the client
+// disconnects, flushing after each call when the writer supports http.Flusher.
+func (ctx *Context) Stream(code int, contentType string, step func(w io.Writer) bool) {
+	ctx.ResponseWriter.Header().Set("Content-Type", contentType)
+	ctx.Status(code)
+
+	flusher, _ := ctx.ResponseWriter.(http.Flusher)
+	done := ctx.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		keepGoing := step(ctx.ResponseWriter)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if !keepGoing {
+			return
+		}
+	}
+}
+
+// SSEvent writes a Server-Sent Event with the given name and data and flushes it to the client.
+// Strings are sent as is and other values are JSON-encoded. Writes to a disconnected client
+// fail silently, so long-lived handlers should stop when ctx.Request.Context().Done() closes.
+func (ctx *Context) SSEvent(name string, data interface{}) {
+	header := ctx.ResponseWriter.Header()
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+	}
+	payload, ok := data.(string)
+	if !ok {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return
+		}
+		payload = string(encoded)
+	}
+
+	var buf bytes.Buffer
+	if name != "" {
+		buf.WriteString("event: " + name + "\n")
+	}
+	for _, line := range strings.Split(payload, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+
+	if _, err := ctx.ResponseWriter.Write(buf.Bytes()); err != nil {
+		return
+	}
+	if flusher, ok := ctx.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // RenderHTML renders an HTML template with the given name and data.
+// Templates loaded with Engine.LoadHTMLGlob or Engine.LoadHTMLFiles are looked up by name;
+// otherwise name is parsed as a file path. On error nothing is written, so the caller can
+// still send a 500.
 func (ctx *Context) RenderHTML(name string, data any) error {
-	tmpl := template.Must(template.ParseFiles(name))
-	return tmpl.Execute(ctx.ResponseWriter, data)
+	tmpl := ctx.engine.templates
+	if tmpl != nil {
+		tmpl = tmpl.Lookup(name)
+		if tmpl == nil {
+			return fmt.Errorf("template %s not found", name)
+		}
+	} else {
+		var err error
+		tmpl, err = template.ParseFiles(name)
+		if err != nil {
+			return fmt.Errorf("parse template %s: %w", name, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("execute template %s: %w", name, err)
+	}
+
+	_, err := ctx.ResponseWriter.Write(buf.Bytes())
+	return err
 }
 
-// Bind binds the request body to the given object.
+// Bind binds the request body to the given object according to its Content-Type:
+// JSON (the default when no type is sent), XML, or a urlencoded or multipart form.
+// Other types, and forms in builds tagged restrum_nobind, return ErrUnsupportedMediaType.
 func (ctx *Context) Bind(d any) error {
-	ctx.ResponseWriter.Header().Set("Content-Type", "application/json")
-	decoder := json.NewDecoder(ctx.Request.Body)
+	contentType, _, _ := mime.ParseMediaType(ctx.Request.Header.Get("Content-Type"))
+	switch {
+	case contentType == "", contentType == "application/json", strings.HasSuffix(contentType, "+json"):
+		return ctx.BindJSON(d)
+	case contentType == "application/xml", contentType == "text/xml", strings.HasSuffix(contentType, "+xml"):
+		return ctx.BindXML(d)
+	case contentType == "application/x-www-form-urlencoded", contentType == "multipart/form-data":
+		return ctx.bindForm(d)
+	default:
+		return ErrUnsupportedMediaType
+	}
+}
+
+// BindJSON decodes the JSON request body into the given object regardless of its Content-Type.
+func (ctx *Context) BindJSON(d any) error {
+	if ctx.config.MaxJSONDepth <= 0 && ctx.config.MaxJSONElements <= 0 {
+		decoder := json.NewDecoder(ctx.Request.Body)
+		return decoder.Decode(d)
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return err
+	}
+	if err = checkJSONLimits(body, ctx.config.MaxJSONDepth, ctx.config.MaxJSONElements); err != nil {
+		return err
+	}
+	return json.Unmarshal(body, d)
+}
+
+// BindXML decodes the XML request body into the given object regardless of its Content-Type.
+func (ctx *Context) BindXML(d any) error {
+	decoder := xml.NewDecoder(ctx.Request.Body)
 	return decoder.Decode(d)
 }
 
+// jsonFrame tracks an open JSON object or array during checkJSONLimits.
+type jsonFrame struct {
+	object bool
+	tokens int
+}
+
+// checkJSONLimits tokenizes the JSON document and enforces the nesting depth and element limits.
+func checkJSONLimits(data []byte, maxDepth, maxElements int) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var stack []jsonFrame
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		delim, isDelim := tok.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			top.tokens++
+			count := top.tokens
+			if top.object {
+				// Keys and values both arrive as tokens; count only the keys.
+				count = (top.tokens + 1) / 2
+			}
+			if maxElements > 0 && count > maxElements {
+				return ErrJSONTooLarge
+			}
+		}
+
+		if isDelim {
+			stack = append(stack, jsonFrame{object: delim == '{'})
+			if maxDepth > 0 && len(stack) > maxDepth {
+				return ErrJSONTooDeep
+			}
+		}
+	}
+}
+
+// AddServerTiming appends a named duration to the Server-Timing response header.
+// It must be called before the response header is written to take effect. With
+// Config.Debug enabled, entries for each middleware are added automatically as well.
+func (ctx *Context) AddServerTiming(name string, d time.Duration) {
+	addServerTiming(ctx.ResponseWriter.Header(), name, d)
+}
+
+// addServerTiming appends a name;dur=milliseconds entry to the Server-Timing header.
+func addServerTiming(header http.Header, name string, d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+	header.Add("Server-Timing", fmt.Sprintf("%s;dur=%.1f", name, ms))
+}
+
+// BearerToken returns the token from an "Authorization: Bearer <token>" header and whether one was present.
+func (ctx *Context) BearerToken() (string, bool) {
+	auth := ctx.Request.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(auth, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// DumpRequest returns the wire representation of the request, optionally including the body.
+// The body is restored afterwards so handlers can still read it.
+func (ctx *Context) DumpRequest(body bool) ([]byte, error) {
+	return httputil.DumpRequest(ctx.Request, body)
+}
+
+// IsTLS reports whether the request was received over TLS.
+func (ctx *Context) IsTLS() bool {
+	return ctx.Request.TLS != nil
+}
+
+// ClientCertificates returns the certificates presented by the client, leaf first.
+func (ctx *Context) ClientCertificates() []*x509.Certificate {
+	if ctx.Request.TLS == nil {
+		return nil
+	}
+	return ctx.Request.TLS.PeerCertificates
+}
+
 // SetCookie sets a cookie in the response.
 func (ctx *Context) SetCookie(cookie *http.Cookie) {
 	http.SetCookie(ctx.ResponseWriter, cookie)
 }
 
-// GetCookie retrieves a cookie from the request by name.
-func (ctx *Context) GetCookie(name string) *string {
+// Cookie sets a cookie with the common attributes and SameSite=Lax. Use SetCookie for
+// full control over the cookie.
+func (ctx *Context) Cookie(name, value string, maxAge int, path string, secure, httpOnly bool) {
+	ctx.SetCookie(&http.Cookie{
+		Name:     name,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     path,
+		Secure:   secure,
+		HttpOnly: httpOnly,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// GetCookie returns the value of the named request cookie, or http.ErrNoCookie if the
+// request has none. It never writes to the response.
+func (ctx *Context) GetCookie(name string) (string, error) {
 	cookie, err := ctx.Request.Cookie(name)
 	if err != nil {
-		if errors.Is(err, http.ErrNoCookie) {
-			http.Error(ctx.ResponseWriter, "cookie not found", http.StatusBadRequest)
-		} else {
-			http.Error(ctx.ResponseWriter, "server error", http.StatusInternalServerError)
-		}
-		return nil
+		return "", err
 	}
-	return &cookie.Value
+	return cookie.Value, nil
+}
+
+// SetSignedCookie sets an HttpOnly, SameSite=Lax cookie for path / whose value is signed
+// with an HMAC-SHA256 of secret, so GetSignedCookie can detect tampering. The value is
+// only encoded, not encrypted, and remains readable by the client.
+func (ctx *Context) SetSignedCookie(name, value, secret string) {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(value))
+	ctx.SetCookie(&http.Cookie{
+		Name:     name,
+		Value:    encoded + "." + signCookie(name, encoded, secret),
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// GetSignedCookie returns the value of a cookie set by SetSignedCookie with the same
+// secret, or ErrInvalidSignature if its value or signature has been altered.
+func (ctx *Context) GetSignedCookie(name, secret string) (string, error) {
+	raw, err := ctx.GetCookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, signature, ok := strings.Cut(raw, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signCookie(name, encoded, secret))) {
+		return "", ErrInvalidSignature
+	}
+	value, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+	return string(value), nil
+}
+
+// signCookie returns the encoded HMAC-SHA256 of the cookie name and encoded value. Signing
+// the name too stops a signed value from being replayed under another cookie.
+func signCookie(name, encoded, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(name + "=" + encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
 }
 
 // DeleteCookie deletes a cookie from the response by name.
@@ -148,51 +946,65 @@ func (ctx *Context) DeleteCookie(name string) {
 	})
 }
 
-// GetIPAddress retrieves the IP address of the client making the request.
+// defaultRemoteIPHeaders are the headers ClientIP reads when Config.RemoteIPHeaders is nil.
+var defaultRemoteIPHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
+// GetIPAddress returns the IP address of the client making the request, as ClientIP does.
 func (ctx *Context) GetIPAddress() string {
-	interfaces, err := net.Interfaces()
-	if err != nil {
-		_, err = fmt.Fprintf(os.Stderr, "Error getting network interfaces: %v\n", err)
-		if err != nil {
-			return ""
-		}
-		os.Exit(1)
+	return ctx.ClientIP()
+}
+
+// ClientIP returns the IP address of the client making the request. When the direct peer
+// is listed in Config.TrustedProxies, the Config.RemoteIPHeaders are checked in order: each
+// is read right to left, skipping trusted hops, and the first untrusted address is the
+// client. Otherwise, or when no header yields one, the peer address is used. It returns
+// an empty string when no address can be determined.
+func (ctx *Context) ClientIP() string {
+	remote := strings.TrimSpace(ctx.Request.RemoteAddr)
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if net.ParseIP(remote) == nil {
+		return ""
+	}
+	if !ctx.isTrustedProxy(remote) {
+		return remote
 	}
 
-	for _, i := range interfaces {
-		adds, err := i.Addrs()
-		if err != nil {
-			_, err = fmt.Fprintf(os.Stderr, "Error getting addresses for interface %v: %v\n", i.Name, err)
-			if err != nil {
-				return ""
+	headers := ctx.config.RemoteIPHeaders
+	if headers == nil {
+		headers = defaultRemoteIPHeaders
+	}
+	for _, name := range headers {
+		hops := strings.Split(strings.Join(ctx.Request.Header.Values(name), ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(hops[i])
+			if net.ParseIP(ip) == nil {
+				break
 			}
-			continue
-		}
-
-		for _, addr := range adds {
-			ip := extractIP(addr)
-			if ip != nil && !ip.IsLoopback() && ip.To4() != nil {
-				return ip.String()
+			if !ctx.isTrustedProxy(ip) {
+				return ip
 			}
 		}
 	}
+	return remote
+}
 
-	_, err = fmt.Fprintln(os.Stderr, "No valid IP address found.")
-	if err != nil {
-		return ""
+// isTrustedProxy reports whether ip matches an address or CIDR range in Config.TrustedProxies.
+func (ctx *Context) isTrustedProxy(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
 	}
-	os.Exit(1)
-	return ""
-}
 
-// extractIP extracts the IP address from a net.Addr.
-func extractIP(addr net.Addr) net.IP {
-	switch v := addr.(type) {
-	case *net.IPNet:
-		return v.IP
-	case *net.IPAddr:
-		return v.IP
-	default:
-		return nil
+	for _, proxy := range ctx.config.TrustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(addr) {
+				return true
+			}
+		} else if trusted := net.ParseIP(proxy); trusted != nil && trusted.Equal(addr) {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,227 @@
+package restrum
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type bindTarget struct {
+	Name string `json:"name" xml:"name" form:"name"`
+	Age  int    `json:"age" xml:"age" form:"age"`
+}
+
+func TestBindByContentType(t *testing.T) {
+	tests := []struct {
+		contentType, body string
+	}{
+		{"", `{"name":"ann","age":30}`},
+		{"application/json; charset=utf-8", `{"name":"ann","age":30}`},
+		{"application/xml", `<bindTarget><name>ann</name><age>30</age></bindTarget>`},
+	}
+	for _, tt := range tests {
+		var got bindTarget
+		var err error
+		e := New()
+		e.POST("/", func(ctx *Context) {
+			err = ctx.Bind(&got)
+			ctx.Status(http.StatusNoContent)
+		})
+
+		var header []string
+		if tt.contentType != "" {
+			header = []string{"Content-Type", tt.contentType}
+		}
+		w := perform(e, http.MethodPost, "/", strings.NewReader(tt.body), header...)
+		if err != nil {
+			t.Errorf("%q: Bind returned %v", tt.contentType, err)
+		}
+		if got != (bindTarget{Name: "ann", Age: 30}) {
+			t.Errorf("%q: bound %+v", tt.contentType, got)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "" {
+			t.Errorf("%q: Bind set response Content-Type %q", tt.contentType, ct)
+		}
+	}
+}
+
+func TestBindUnsupportedMediaType(t *testing.T) {
+	var err error
+	e := New()
+	e.POST("/", func(ctx *Context) { err = ctx.Bind(&bindTarget{}) })
+
+	perform(e, http.MethodPost, "/", strings.NewReader("name,age\nann,30\n"), "Content-Type", "text/csv")
+	if !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Bind returned %v, want %v", err, ErrUnsupportedMediaType)
+	}
+}
+
+func TestSaveUploadedFile(t *testing.T) {
+	content := []byte("hello, upload")
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	mw.Close()
+
+	dst := filepath.Join(t.TempDir(), "uploads", "hello.txt")
+	var saveErr error
+	e := New()
+	e.POST("/upload", func(ctx *Context) {
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			saveErr = err
+			return
+		}
+		saveErr = ctx.SaveUploadedFile(file, dst)
+	})
+
+	perform(e, http.MethodPost, "/upload", &body, "Content-Type", mw.FormDataContentType())
+	if saveErr != nil {
+		t.Fatalf("upload failed: %v", saveErr)
+	}
+	saved, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(saved, content) {
+		t.Errorf("saved %q, want %q", saved, content)
+	}
+}
+
+func TestFormValueReadsBodyForEveryMethod(t *testing.T) {
+	for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		var got string
+		e := New()
+		e.AddRoutes(method, "/", func(ctx *Context) { got = ctx.FormValue("name") })
+
+		perform(e, method, "/", strings.NewReader("name=ann"), "Content-Type", "application/x-www-form-urlencoded")
+		if got != "ann" {
+			t.Errorf("%s: FormValue = %q, want %q", method, got, "ann")
+		}
+	}
+}
+
+func TestParseFormRejectsOversizedBody(t *testing.T) {
+	var err error
+	e := New()
+	e.DELETE("/", func(ctx *Context) { err = ctx.parseForm() })
+
+	body := "name=" + strings.Repeat("a", maxFormBodySize)
+	perform(e, http.MethodDelete, "/", strings.NewReader(body), "Content-Type", "application/x-www-form-urlencoded")
+	if err == nil {
+		t.Error("parseForm accepted a body over the limit")
+	}
+}
+
+func TestXML(t *testing.T) {
+	type item struct {
+		XMLName struct{} `xml:"item"`
+		ID      int      `xml:"id,attr"`
+		Name    string   `xml:"name"`
+	}
+	e := New()
+	e.GET("/", func(ctx *Context) { ctx.XML(http.StatusCreated, item{ID: 7, Name: "pen"}) })
+
+	w := perform(e, http.MethodGet, "/", nil)
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/xml" {
+		t.Errorf("Content-Type = %q, want %q", ct, "application/xml")
+	}
+	if body, want := w.Body.String(), `<item id="7"><name>pen</name></item>`; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	e := New()
+	e.GET("/old", func(ctx *Context) { ctx.Redirect(http.StatusFound, "/new") })
+
+	w := perform(e, http.MethodGet, "/old", nil)
+	if w.Code != http.StatusFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusFound)
+	}
+	if loc := w.Header().Get("Location"); loc != "/new" {
+		t.Errorf("Location = %q, want %q", loc, "/new")
+	}
+}
+
+func TestRedirectPanicsOnNonRedirectCode(t *testing.T) {
+	var recovered any
+	e := New()
+	e.GET("/", func(ctx *Context) {
+		defer func() { recovered = recover() }()
+		ctx.Redirect(http.StatusOK, "/new")
+	})
+
+	perform(e, http.MethodGet, "/", nil)
+	if recovered == nil {
+		t.Error("Redirect with status 200 did not panic")
+	}
+}
+
+func TestJSONP(t *testing.T) {
+	e := New()
+	e.GET("/", func(ctx *Context) {
+		ctx.JSONP(http.StatusOK, ctx.Request.URL.Query().Get("callback"), map[string]int{"n": 1})
+	})
+
+	w := perform(e, http.MethodGet, "/?callback=cb", nil)
+	if body, want := w.Body.String(), `cb({"n":1});`; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/javascript" {
+		t.Errorf("Content-Type = %q, want %q", ct, "application/javascript")
+	}
+
+	w = perform(e, http.MethodGet, "/?callback=alert(1)//", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unsafe callback: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestIndentedJSON(t *testing.T) {
+	e := New()
+	e.GET("/", func(ctx *Context) { ctx.IndentedJSON(http.StatusOK, map[string]int{"n": 1}) })
+
+	w := perform(e, http.MethodGet, "/", nil)
+	if body, want := w.Body.String(), "{\n  \"n\": 1\n}\n"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want %q", ct, "application/json")
+	}
+}
+
+func TestTypedGetSet(t *testing.T) {
+	type user struct{ Name string }
+	ctx := &Context{}
+	Set(ctx, "user", &user{Name: "ann"})
+	Set(ctx, "count", 3)
+
+	if u, ok := Get[*user](ctx, "user"); !ok || u.Name != "ann" {
+		t.Errorf("Get[*user] = %v, %v, want ann, true", u, ok)
+	}
+	if n, ok := Get[int](ctx, "count"); !ok || n != 3 {
+		t.Errorf("Get[int] = %v, %v, want 3, true", n, ok)
+	}
+	if s, ok := Get[string](ctx, "count"); ok || s != "" {
+		t.Errorf("Get[string] of an int = %q, %v, want \"\", false", s, ok)
+	}
+	if _, ok := Get[int](ctx, "missing"); ok {
+		t.Error("Get of a missing key reported true")
+	}
+	if v, _ := ctx.Get("count"); v != 3 {
+		t.Errorf("untyped Get = %v, want 3", v)
+	}
+}
@@ -0,0 +1,64 @@
+package restrum
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DebugDump creates a middleware that logs each incoming request, optionally with its body.
+// It does nothing unless Config.Debug is enabled.
+func DebugDump(body bool) HandlerFunc {
+	return func(ctx *Context) {
+		if ctx.config.Debug {
+			dump, err := ctx.DumpRequest(body)
+			if err != nil {
+				log.Printf("debug dump failed: %v", err)
+			} else {
+				log.Printf("debug dump:\n%s", dump)
+			}
+		}
+		ctx.Next()
+	}
+}
+
+// phase is a middleware or handler running under Config.Debug.
+type phase struct {
+	name  string
+	start time.Time
+}
+
+// timePhase runs handler, keeping it on the stack of phases while it runs.
+func (ctx *Context) timePhase(handler HandlerFunc) {
+	ctx.phases = append(ctx.phases, phase{name: timingName(handlerName(handler)), start: time.Now()})
+	defer func() { ctx.phases = ctx.phases[:len(ctx.phases)-1] }()
+	handler(ctx)
+}
+
+// addPhaseTimings adds a Server-Timing entry to header for each running middleware and
+// handler. The header is sent before the chain unwinds, so an entry covers the time from
+// the start of its phase until it called Next, or until now for the innermost one; work a
+// middleware does after Next returns is not included.
+func (ctx *Context) addPhaseTimings(header http.Header) {
+	now := time.Now()
+	for i, p := range ctx.phases {
+		end := now
+		if i+1 < len(ctx.phases) {
+			end = ctx.phases[i+1].start
+		}
+		addServerTiming(header, p.name, end.Sub(p.start))
+	}
+}
+
+// timingName turns a function name into a Server-Timing metric name: its last path
+// element, e.g., restrum.Logger.func1, with characters not allowed in a token replaced.
+func timingName(name string) string {
+	name = name[strings.LastIndexByte(name, '/')+1:]
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
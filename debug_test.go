@@ -0,0 +1,47 @@
+package restrum
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func slowAuth(ctx *Context) {
+	time.Sleep(5 * time.Millisecond)
+	ctx.Next()
+}
+
+func TestDebugServerTiming(t *testing.T) {
+	for _, debug := range []bool{true, false} {
+		e := New(Config{Debug: debug})
+		e.Use(slowAuth)
+		e.GET("/", func(ctx *Context) {
+			ctx.AddServerTiming("db", 2*time.Millisecond)
+			ctx.String(http.StatusOK, "ok")
+		})
+
+		timings := perform(e, http.MethodGet, "/", nil).Header().Values("Server-Timing")
+		if !debug {
+			if len(timings) != 1 || timings[0] != "db;dur=2.0" {
+				t.Errorf("debug off: Server-Timing = %q, want only the manual entry", timings)
+			}
+			continue
+		}
+
+		if len(timings) != 3 || timings[0] != "db;dur=2.0" {
+			t.Fatalf("Server-Timing = %q, want the manual entry and one per phase", timings)
+		}
+		auth, found := strings.CutPrefix(timings[1], "restrum.slowAuth;dur=")
+		if !found {
+			t.Fatalf("first phase = %q, want restrum.slowAuth", timings[1])
+		}
+		if ms, err := strconv.ParseFloat(auth, 64); err != nil || ms < 5 {
+			t.Errorf("slowAuth took %sms, want at least 5ms", auth)
+		}
+		if !strings.HasPrefix(timings[2], "restrum.TestDebugServerTiming.func1;dur=") {
+			t.Errorf("second phase = %q, want the handler", timings[2])
+		}
+	}
+}
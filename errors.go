@@ -0,0 +1,45 @@
+package restrum
+
+import "net/http"
+
+// Error records err against the request for an ErrorHandler further up the chain to
+// render. It does not write to the response.
+func (ctx *Context) Error(err error) {
+	if err != nil {
+		ctx.errs = append(ctx.errs, err)
+	}
+}
+
+// Errors returns a copy of the errors recorded with Error, in the order they were added.
+func (ctx *Context) Errors() []error {
+	errs := make([]error, len(ctx.errs))
+	copy(errs, ctx.errs)
+	return errs
+}
+
+// ErrorHandler creates a middleware that, once the rest of the chain returns, passes any
+// errors recorded with Context.Error to handle. A nil handle responds 500 with a JSON
+// body of the form {"errors": ["..."]} unless a response has already been written.
+func ErrorHandler(handle func(ctx *Context, errs []error)) HandlerFunc {
+	if handle == nil {
+		handle = renderErrors
+	}
+	return func(ctx *Context) {
+		ctx.Next()
+		if len(ctx.errs) > 0 {
+			handle(ctx, ctx.Errors())
+		}
+	}
+}
+
+// renderErrors is the default ErrorHandler rendering.
+func renderErrors(ctx *Context, errs []error) {
+	if ctx.Written() {
+		return
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	ctx.JSON(http.StatusInternalServerError, map[string][]string{"errors": msgs})
+}
@@ -0,0 +1,93 @@
+package restrum
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// acceptSpec is a single media range from an Accept header.
+type acceptSpec struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parses an Accept header into its media ranges, defaulting q to 1.
+func parseAccept(header string) []acceptSpec {
+	var specs []acceptSpec
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		specs = append(specs, acceptSpec{mediaType: mediaType, q: q})
+	}
+	return specs
+}
+
+// matchMediaType reports whether the media range, which may contain wildcards, covers offer.
+func matchMediaType(mediaRange, offer string) bool {
+	if mediaRange == "*/*" || mediaRange == offer {
+		return true
+	}
+	rangeType, rangeSub, _ := strings.Cut(mediaRange, "/")
+	offerType, _, _ := strings.Cut(offer, "/")
+	return rangeSub == "*" && rangeType == offerType
+}
+
+// ContentTypeGuard creates a middleware that responds 415 when a POST, PUT or PATCH request
+// carries a Content-Type other than the allowed media types.
+func ContentTypeGuard(allowed ...string) HandlerFunc {
+	return func(ctx *Context) {
+		switch ctx.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			ctx.Next()
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(ctx.Request.Header.Get("Content-Type"))
+		if err == nil {
+			for _, a := range allowed {
+				if strings.EqualFold(a, mediaType) {
+					ctx.Next()
+					return
+				}
+			}
+		}
+		http.Error(ctx.ResponseWriter, "unsupported media type", http.StatusUnsupportedMediaType)
+	}
+}
+
+// AcceptGuard creates a middleware that responds 406 when the request's Accept header
+// does not accept any of the offered media types. A missing Accept header accepts anything.
+func AcceptGuard(offered ...string) HandlerFunc {
+	return func(ctx *Context) {
+		accept := ctx.Request.Header.Get("Accept")
+		if accept == "" {
+			ctx.Next()
+			return
+		}
+
+		for _, spec := range parseAccept(accept) {
+			if spec.q <= 0 {
+				continue
+			}
+			for _, offer := range offered {
+				if matchMediaType(spec.mediaType, strings.ToLower(offer)) {
+					ctx.Next()
+					return
+				}
+			}
+		}
+		http.Error(ctx.ResponseWriter, "not acceptable", http.StatusNotAcceptable)
+	}
+}
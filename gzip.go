@@ -0,0 +1,199 @@
+package restrum
+
+import (
+	"bufio"
+	"compress/gzip"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// gzipMinLength is the smallest body worth compressing; shorter bodies are sent as is.
+const gzipMinLength = 1024
+
+// gzipSkipTypes lists the content type prefixes that are already compressed.
+var gzipSkipTypes = []string{
+	"image/", "video/", "audio/", "font/woff",
+	"application/gzip", "application/zip", "application/x-gzip", "application/zstd",
+	"application/x-7z-compressed", "application/x-rar-compressed", "application/pdf",
+}
+
+// Gzip creates a middleware that gzip-compresses responses with the given compression
+// level for clients that accept it. Bodies shorter than 1 KB, already-compressed content
+// types, and responses that set their own Content-Encoding are sent uncompressed. It
+// panics if level is not a valid gzip level.
+func Gzip(level int) HandlerFunc {
+	if _, err := gzip.NewWriterLevel(nil, level); err != nil {
+		panic("restrum: " + err.Error())
+	}
+	pool := &sync.Pool{New: func() interface{} {
+		gz, _ := gzip.NewWriterLevel(nil, level)
+		return gz
+	}}
+
+	return func(ctx *Context) {
+		ctx.ResponseWriter.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(ctx.Request) || headerHasToken(ctx.Request.Header, "Connection", "upgrade") {
+			ctx.Next()
+			return
+		}
+
+		writer := &gzipWriter{ResponseWriter: ctx.ResponseWriter, pool: pool}
+		original := ctx.ResponseWriter
+		ctx.ResponseWriter = writer
+		defer func() {
+			writer.close()
+			ctx.ResponseWriter = original
+		}()
+		ctx.Next()
+	}
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows a gzip response. An
+// explicit gzip entry takes precedence over a * entry.
+func acceptsGzip(req *http.Request) bool {
+	q := 0.0
+	for _, spec := range parseAccept(req.Header.Get("Accept-Encoding")) {
+		if spec.mediaType == "gzip" {
+			return spec.q > 0
+		} else if spec.mediaType == "*" {
+			q = spec.q
+		}
+	}
+	return q > 0
+}
+
+// gzipWriter buffers the start of the body until it knows whether the response should be
+// compressed, then either compresses the rest or passes it through.
+type gzipWriter struct {
+	http.ResponseWriter
+	pool *sync.Pool
+	gz   *gzip.Writer
+
+	status  int
+	buf     []byte
+	decided bool
+}
+
+// WriteHeader records the status code; it is sent once the body is known to need
+// compression or not. Informational 1xx codes other than 101 are passed on at once.
+func (w *gzipWriter) WriteHeader(code int) {
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 && !w.decided {
+		w.status = code
+	}
+}
+
+// Write buffers data until enough has arrived to decide on compression.
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < gzipMinLength {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush sends what has been written so far, compressed if applicable, so streaming
+// responses keep working.
+func (w *gzipWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack passes through to the underlying writer so upgraded connections still work.
+func (w *gzipWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide writes the header, compressed if compress is set and the response qualifies, and
+// then the buffered body.
+func (w *gzipWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if compress && w.compressible() {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+		w.gz = w.pool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+		w.ResponseWriter.WriteHeader(w.status)
+		_, err := w.gz.Write(w.buf)
+		w.buf = nil
+		return err
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// compressible reports whether the status and headers allow compressing the body.
+func (w *gzipWriter) compressible() bool {
+	if w.status < http.StatusOK || w.status == http.StatusNoContent ||
+		w.status == http.StatusNotModified || w.status == http.StatusPartialContent {
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	for _, prefix := range gzipSkipTypes {
+		if strings.HasPrefix(contentType, prefix) && contentType != "image/svg+xml" {
+			return false
+		}
+	}
+	return true
+}
+
+// close sends any body still buffered, uncompressed if it never reached the minimum
+// length, and returns the gzip writer to the pool.
+func (w *gzipWriter) close() {
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			return
+		}
+		w.decide(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		w.pool.Put(w.gz)
+		w.gz = nil
+	}
+}
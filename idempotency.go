@@ -0,0 +1,169 @@
+package restrum
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CachedResponse is a response captured by the Idempotency middleware for replay.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// IdempotencyStore persists responses keyed by the method, route pattern, caller scope and
+// Idempotency-Key header of the request.
+type IdempotencyStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse)
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore safe for concurrent use.
+type MemoryIdempotencyStore struct {
+	mu    sync.RWMutex
+	items map[string]*CachedResponse
+}
+
+// NewMemoryIdempotencyStore creates an empty MemoryIdempotencyStore.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{items: make(map[string]*CachedResponse)}
+}
+
+// Get returns the response stored for key, if any.
+func (s *MemoryIdempotencyStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	resp, ok := s.items[key]
+	return resp, ok
+}
+
+// Set stores resp under key.
+func (s *MemoryIdempotencyStore) Set(key string, resp *CachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = resp
+}
+
+// captureWriter passes writes through while recording the status code and body.
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status code before passing it on.
+func (w *captureWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write records the body before passing it on.
+func (w *captureWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// IdempotencyConfig configures the Idempotency middleware.
+type IdempotencyConfig struct {
+	// Store holds the stored responses. It is required.
+	Store IdempotencyStore
+	// Scope identifies the caller an Idempotency-Key belongs to, so one caller's key never
+	// replays another's response. It defaults to the client IP; set it to the authenticated
+	// user when callers may share an address.
+	Scope func(ctx *Context) string
+}
+
+// Idempotency creates a middleware that replays the stored response for a repeated
+// Idempotency-Key header from the same client IP, and stores the response of the first
+// request carrying that key.
+func Idempotency(store IdempotencyStore) HandlerFunc {
+	return IdempotencyWithConfig(IdempotencyConfig{Store: store})
+}
+
+// IdempotencyWithConfig creates an idempotency middleware with the given configuration.
+// Responses are stored per method, route pattern and scope, so a key reused on another
+// endpoint or by another caller is treated as new. A request arriving while another with
+// the same key is still being handled in this process gets a 409. Only the headers set
+// further down the chain are replayed, except Set-Cookie, which is never stored. Server
+// errors (5xx) are not stored so the client can retry them. It panics if config.Store is nil.
+func IdempotencyWithConfig(config IdempotencyConfig) HandlerFunc {
+	if config.Store == nil {
+		panic("restrum: idempotency store is required")
+	}
+	store := config.Store
+	scope := config.Scope
+	if scope == nil {
+		scope = (*Context).ClientIP
+	}
+	var mu sync.Mutex
+	inFlight := make(map[string]struct{})
+
+	return func(ctx *Context) {
+		key := ctx.Request.Header.Get("Idempotency-Key")
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		// Header values cannot contain NUL, so the parts cannot run into each other.
+		key = strings.Join([]string{ctx.HTTPMethod, ctx.RoutePattern(), scope(ctx), key}, "\x00")
+
+		mu.Lock()
+		if _, busy := inFlight[key]; busy {
+			mu.Unlock()
+			http.Error(ctx.ResponseWriter, "request with this idempotency key is in progress", http.StatusConflict)
+			return
+		}
+		inFlight[key] = struct{}{}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			delete(inFlight, key)
+			mu.Unlock()
+		}()
+
+		if cached, ok := store.Get(key); ok {
+			header := ctx.ResponseWriter.Header()
+			for k, v := range cached.Header {
+				header[k] = append([]string(nil), v...)
+			}
+			ctx.Data(cached.StatusCode, cached.Body)
+			return
+		}
+
+		writer := &captureWriter{ResponseWriter: ctx.ResponseWriter}
+		original := ctx.ResponseWriter
+		before := original.Header().Clone()
+		ctx.ResponseWriter = writer
+		ctx.Next()
+		ctx.ResponseWriter = original
+
+		if writer.status != 0 && writer.status < http.StatusInternalServerError {
+			store.Set(key, &CachedResponse{
+				StatusCode: writer.status,
+				Header:     changedHeaders(before, original.Header()),
+				Body:       writer.body.Bytes(),
+			})
+		}
+	}
+}
+
+// changedHeaders returns the headers in after that were added or changed since before,
+// leaving out Set-Cookie so a replay never hands out the original caller's cookies.
+func changedHeaders(before, after http.Header) http.Header {
+	changed := make(http.Header)
+	for k, v := range after {
+		if k != "Set-Cookie" && !slices.Equal(before[k], v) {
+			changed[k] = append([]string(nil), v...)
+		}
+	}
+	return changed
+}
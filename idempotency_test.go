@@ -0,0 +1,91 @@
+package restrum
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIdempotencyReplay(t *testing.T) {
+	var calls atomic.Int32
+	handler := func(ctx *Context) {
+		n := calls.Add(1)
+		ctx.SetHeader("X-Call", strconv.Itoa(int(n)))
+		ctx.String(http.StatusCreated, "charge "+strconv.Itoa(int(n)))
+	}
+
+	e := New()
+	e.Use(RequestID(), Idempotency(NewMemoryIdempotencyStore()))
+	e.POST("/charges", handler)
+	e.POST("/refunds", handler)
+
+	first := perform(e, http.MethodPost, "/charges", nil, "Idempotency-Key", "k1")
+	again := perform(e, http.MethodPost, "/charges", nil, "Idempotency-Key", "k1")
+	if again.Code != http.StatusCreated || again.Body.String() != "charge 1" || again.Header().Get("X-Call") != "1" {
+		t.Errorf("replay = %d %q X-Call=%q, want 201 \"charge 1\" X-Call=1", again.Code, again.Body.String(), again.Header().Get("X-Call"))
+	}
+	if id := again.Header().Get("X-Request-ID"); id == "" || id == first.Header().Get("X-Request-ID") {
+		t.Errorf("replay X-Request-ID = %q, want a fresh ID", id)
+	}
+
+	other := perform(e, http.MethodPost, "/refunds", nil, "Idempotency-Key", "k1")
+	if other.Body.String() != "charge 2" {
+		t.Errorf("same key on another route = %q, want a new response", other.Body.String())
+	}
+}
+
+func TestIdempotencyConcurrentDuplicate(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	e := New()
+	e.Use(Idempotency(NewMemoryIdempotencyStore()))
+	e.POST("/charges", func(ctx *Context) {
+		close(started)
+		<-release
+		ctx.String(http.StatusCreated, "ok")
+	})
+
+	done := make(chan int)
+	go func() {
+		done <- perform(e, http.MethodPost, "/charges", nil, "Idempotency-Key", "k1").Code
+	}()
+	<-started
+
+	if w := perform(e, http.MethodPost, "/charges", nil, "Idempotency-Key", "k1"); w.Code != http.StatusConflict {
+		t.Errorf("concurrent duplicate status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	close(release)
+	if code := <-done; code != http.StatusCreated {
+		t.Errorf("first request status = %d, want %d", code, http.StatusCreated)
+	}
+}
+
+func TestIdempotencyScopedPerCaller(t *testing.T) {
+	var calls atomic.Int32
+	e := New()
+	e.Use(IdempotencyWithConfig(IdempotencyConfig{
+		Store: NewMemoryIdempotencyStore(),
+		Scope: func(ctx *Context) string { return ctx.Request.Header.Get("X-User") },
+	}))
+	e.POST("/charges", func(ctx *Context) {
+		n := calls.Add(1)
+		http.SetCookie(ctx.ResponseWriter, &http.Cookie{Name: "session", Value: strconv.Itoa(int(n))})
+		ctx.String(http.StatusCreated, "charge "+strconv.Itoa(int(n)))
+	})
+
+	perform(e, http.MethodPost, "/charges", nil, "Idempotency-Key", "k1", "X-User", "alice")
+	replay := perform(e, http.MethodPost, "/charges", nil, "Idempotency-Key", "k1", "X-User", "alice")
+	if replay.Body.String() != "charge 1" {
+		t.Errorf("same caller = %q, want the stored response", replay.Body.String())
+	}
+	if cookie := replay.Header().Get("Set-Cookie"); cookie != "" {
+		t.Errorf("replay Set-Cookie = %q, want none", cookie)
+	}
+
+	other := perform(e, http.MethodPost, "/charges", nil, "Idempotency-Key", "k1", "X-User", "bob")
+	if other.Body.String() != "charge 2" {
+		t.Errorf("another caller = %q, want a new response", other.Body.String())
+	}
+}
@@ -0,0 +1,105 @@
+package restrum
+
+import (
+	"io"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// defaultLogFormat is the line format used when LoggerConfig.Format is empty.
+const defaultLogFormat = "{method} {path} {status} {latency}"
+
+// Preset LoggerConfig.Format layouts for log analysis tools that expect the NCSA formats.
+const (
+	// LogFormatCommon is the NCSA Common Log Format:
+	// host ident authuser [date] "request" status bytes.
+	LogFormatCommon = `{ip} - {user} [{time}] "{method} {uri} {proto}" {status} {size}`
+	// LogFormatCombined is the NCSA Combined Log Format, the Common format followed by the
+	// quoted referer and user agent.
+	LogFormatCombined = LogFormatCommon + ` "{referer}" "{user_agent}"`
+)
+
+// statusClientClosedRequest is the nonstandard status nginx logs for a client that
+// disconnected before the response was sent.
+const statusClientClosedRequest = 499
+
+// clfTimeLayout is the time layout of the NCSA log formats.
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// clfQuote escapes backslashes and quotes in values logged inside quotes.
+var clfQuote = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// LoggerConfig configures the Logger middleware.
+type LoggerConfig struct {
+	// Output receives the log lines. It defaults to the standard logger's output.
+	Output io.Writer
+	// Format is the line layout. The placeholders {method}, {path}, {status},
+	// {latency} and {ip} are replaced per request, as are {uri} and {proto} from the
+	// request line, {time} (the start time in the NCSA layout), {size} (body bytes, or -
+	// when none were sent), {user} (the name BasicAuth stored, or -), {referer} and
+	// {user_agent}. LogFormatCommon and LogFormatCombined are ready-made layouts; lines in
+	// those formats carry their own date, so they are written without the log timestamp.
+	// A request whose client disconnected mid-response is logged with status 499, as nginx
+	// does, rather than the status the handler sent.
+	Format string
+}
+
+// Logger creates a middleware that logs the method, path, status and latency of each request.
+func Logger() HandlerFunc {
+	return LoggerWithConfig(LoggerConfig{})
+}
+
+// LoggerWithConfig creates a request logging middleware with the given configuration.
+func LoggerWithConfig(config LoggerConfig) HandlerFunc {
+	format := config.Format
+	if format == "" {
+		format = defaultLogFormat
+	}
+	flags := log.LstdFlags
+	if format == LogFormatCommon || format == LogFormatCombined {
+		flags = 0
+	}
+	logger := log.Default()
+	if config.Output != nil || flags != log.LstdFlags {
+		output := config.Output
+		if output == nil {
+			output = log.Writer()
+		}
+		logger = log.New(output, "", flags)
+	}
+
+	return func(ctx *Context) {
+		ctx.Next()
+
+		size, user, uri := "-", "-", ctx.Request.RequestURI
+		if uri == "" {
+			uri = ctx.Request.URL.RequestURI()
+		}
+		if n := ctx.Size(); n > 0 {
+			size = strconv.Itoa(n)
+		}
+		if name := ctx.GetString("user"); name != "" {
+			user = name
+		}
+		status := ctx.ResponseCode
+		if ctx.ClientGone() {
+			status = statusClientClosedRequest
+		}
+		line := strings.NewReplacer(
+			"{method}", ctx.HTTPMethod,
+			"{path}", ctx.RoutePath,
+			"{status}", strconv.Itoa(status),
+			"{latency}", ctx.Since().String(),
+			"{ip}", ctx.ClientIP(),
+			"{uri}", clfQuote.Replace(uri),
+			"{proto}", ctx.Request.Proto,
+			"{time}", ctx.StartTime.Format(clfTimeLayout),
+			"{size}", size,
+			"{user}", user,
+			"{referer}", clfQuote.Replace(ctx.Request.Referer()),
+			"{user_agent}", clfQuote.Replace(ctx.Request.UserAgent()),
+		).Replace(format)
+		logger.Print(line)
+	}
+}
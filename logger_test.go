@@ -0,0 +1,48 @@
+package restrum
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLoggerNCSAFormats(t *testing.T) {
+	tests := []struct {
+		format, want string
+	}{
+		{LogFormatCommon, `192.0.2.1 - ann [10/Oct/2000:13:55:36 -0700] "GET /items?page=2 HTTP/1.1" 200 5` + "\n"},
+		{LogFormatCombined, `192.0.2.1 - ann [10/Oct/2000:13:55:36 -0700] "GET /items?page=2 HTTP/1.1" 200 5 "https://ref.example/" "agent \"quoted\""` + "\n"},
+	}
+	start := time.Date(2000, time.October, 10, 13, 55, 36, 0, time.FixedZone("", -7*60*60))
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		e := New()
+		e.Use(func(ctx *Context) {
+			ctx.StartTime = start
+			ctx.Next()
+		}, LoggerWithConfig(LoggerConfig{Output: &out, Format: tt.format}))
+		e.GET("/items", func(ctx *Context) {
+			ctx.Set("user", "ann")
+			ctx.String(http.StatusOK, "items")
+		})
+
+		perform(e, http.MethodGet, "/items?page=2", nil, "Referer", "https://ref.example/", "User-Agent", `agent "quoted"`)
+		if out.String() != tt.want {
+			t.Errorf("got  %q\nwant %q", out.String(), tt.want)
+		}
+	}
+}
+
+func TestLoggerCommonFormatEmptyBody(t *testing.T) {
+	var out bytes.Buffer
+	e := New()
+	e.Use(LoggerWithConfig(LoggerConfig{Output: &out, Format: LogFormatCommon}))
+	e.GET("/", func(ctx *Context) { ctx.NoContent() })
+
+	perform(e, http.MethodGet, "/", nil)
+	if want := `" 204 -` + "\n"; !bytes.HasSuffix(out.Bytes(), []byte(want)) {
+		t.Errorf("line %q does not end in %q", out.String(), want)
+	}
+}
@@ -0,0 +1,140 @@
+package restrum
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metricsBuckets are the upper bounds, in seconds, of the request duration histogram.
+var metricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics creates a middleware that counts requests by method, route pattern and status,
+// and records their durations by method and route pattern, for Engine.MetricsHandler to
+// expose when it is used on the root group. Requests that match no route, including the
+// redirects, 404s and 405s the router answers itself, are recorded with an empty path.
+func Metrics() HandlerFunc {
+	return func(ctx *Context) {
+		ctx.Next()
+		ctx.engine.metrics.observe(ctx.HTTPMethod, ctx.RoutePattern(), ctx.ResponseCode, ctx.Since().Seconds())
+	}
+}
+
+// MetricsHandler returns a handler serving the metrics recorded by the Metrics middleware
+// in the Prometheus text exposition format.
+func (e *Engine) MetricsHandler() HandlerFunc {
+	return func(ctx *Context) {
+		ctx.ResponseWriter.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		ctx.Data(http.StatusOK, e.metrics.render())
+	}
+}
+
+// requestKey identifies a request counter.
+type requestKey struct {
+	method, path string
+	status       int
+}
+
+// durationKey identifies a request duration histogram.
+type durationKey struct {
+	method, path string
+}
+
+// histogram holds the cumulative bucket counts, sum and count of observed durations.
+type histogram struct {
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+// metricsRegistry is the in-memory store behind Metrics and MetricsHandler.
+type metricsRegistry struct {
+	mu        sync.Mutex
+	requests  map[requestKey]uint64
+	durations map[durationKey]*histogram
+}
+
+// observe records one request.
+func (m *metricsRegistry) observe(method, path string, status int, seconds float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.requests == nil {
+		m.requests = make(map[requestKey]uint64)
+		m.durations = make(map[durationKey]*histogram)
+	}
+	m.requests[requestKey{method, path, status}]++
+
+	h, ok := m.durations[durationKey{method, path}]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(metricsBuckets))}
+		m.durations[durationKey{method, path}] = h
+	}
+	for i, bound := range metricsBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// render formats the recorded metrics, sorted by their labels.
+func (m *metricsRegistry) render() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP restrum_requests_total Total number of HTTP requests.\n")
+	b.WriteString("# TYPE restrum_requests_total counter\n")
+	requests := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		requests = append(requests, k)
+	}
+	slices.SortFunc(requests, func(a, b requestKey) int {
+		if c := strings.Compare(a.path, b.path); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.method, b.method); c != 0 {
+			return c
+		}
+		return a.status - b.status
+	})
+	for _, k := range requests {
+		fmt.Fprintf(&b, "restrum_requests_total{method=%s,path=%s,status=\"%d\"} %d\n",
+			labelValue(k.method), labelValue(k.path), k.status, m.requests[k])
+	}
+
+	b.WriteString("# HELP restrum_request_duration_seconds Duration of HTTP requests in seconds.\n")
+	b.WriteString("# TYPE restrum_request_duration_seconds histogram\n")
+	durations := make([]durationKey, 0, len(m.durations))
+	for k := range m.durations {
+		durations = append(durations, k)
+	}
+	slices.SortFunc(durations, func(a, b durationKey) int {
+		if c := strings.Compare(a.path, b.path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.method, b.method)
+	})
+	for _, k := range durations {
+		h := m.durations[k]
+		labels := "method=" + labelValue(k.method) + ",path=" + labelValue(k.path)
+		for i, bound := range metricsBuckets {
+			fmt.Fprintf(&b, "restrum_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				labels, strconv.FormatFloat(bound, 'g', -1, 64), h.buckets[i])
+		}
+		fmt.Fprintf(&b, "restrum_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(&b, "restrum_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "restrum_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+	return []byte(b.String())
+}
+
+// labelValue quotes a Prometheus label value, escaping backslashes, quotes and newlines.
+func labelValue(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v) + `"`
+}
@@ -0,0 +1,30 @@
+package restrum
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMetricsRecordsRouterResponses(t *testing.T) {
+	e := New()
+	e.Use(Metrics())
+	e.GET("/items", func(ctx *Context) { ctx.NoContent() })
+
+	perform(e, http.MethodGet, "/items", nil)
+	perform(e, http.MethodGet, "/missing", nil)
+	perform(e, http.MethodDelete, "/items", nil)
+	perform(e, http.MethodGet, "/items/", nil)
+
+	metrics := string(e.metrics.render())
+	for _, want := range []string{
+		`restrum_requests_total{method="GET",path="/items",status="204"} 1`,
+		`restrum_requests_total{method="GET",path="",status="404"} 1`,
+		`restrum_requests_total{method="DELETE",path="",status="405"} 1`,
+		`restrum_requests_total{method="GET",path="",status="301"} 1`,
+	} {
+		if !strings.Contains(metrics, want+"\n") {
+			t.Errorf("metrics missing %s\n%s", want, metrics)
+		}
+	}
+}
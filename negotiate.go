@@ -0,0 +1,107 @@
+package restrum
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// negotiatePreference orders the offers Negotiate picks from when the Accept header ranks
+// several equally; other media types follow in alphabetical order.
+var negotiatePreference = []string{"application/json", "application/xml", "text/xml", "text/html", "text/plain"}
+
+// Negotiate sends data for the offered media type the request's Accept header ranks
+// highest, with the given status code. offers maps media types to the data to send:
+// JSON and XML types are encoded, text/html and text/plain send a string as is, and any
+// other type sends a string or []byte with that Content-Type. Without an Accept header the
+// first offer in the order JSON, XML, HTML, plain text, then alphabetical is used; when
+// nothing is acceptable it responds 406.
+func (ctx *Context) Negotiate(code int, offers map[string]interface{}) {
+	ctx.ResponseWriter.Header().Add("Vary", "Accept")
+
+	types := make([]string, 0, len(offers))
+	for t := range offers {
+		types = append(types, t)
+	}
+	slices.SortFunc(types, func(a, b string) int {
+		ia, ib := slices.Index(negotiatePreference, a), slices.Index(negotiatePreference, b)
+		if ia < 0 {
+			ia = len(negotiatePreference)
+		}
+		if ib < 0 {
+			ib = len(negotiatePreference)
+		}
+		if ia != ib {
+			return ia - ib
+		}
+		return strings.Compare(a, b)
+	})
+
+	offer := ""
+	if accept := ctx.Request.Header.Get("Accept"); accept == "" {
+		if len(types) > 0 {
+			offer = types[0]
+		}
+	} else {
+		specs := parseAccept(accept)
+		best := 0.0
+		for _, t := range types {
+			if q := acceptQuality(specs, strings.ToLower(t)); q > best {
+				offer, best = t, q
+			}
+		}
+	}
+	if offer == "" {
+		http.Error(ctx.ResponseWriter, "not acceptable", http.StatusNotAcceptable)
+		return
+	}
+
+	data := offers[offer]
+	mediaType, _, _ := mime.ParseMediaType(offer)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		ctx.JSON(code, data)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		ctx.XML(code, data)
+	case mediaType == "text/html":
+		ctx.HTML(code, fmt.Sprint(data))
+	case mediaType == "text/plain":
+		ctx.String(code, fmt.Sprint(data))
+	default:
+		var body []byte
+		switch v := data.(type) {
+		case []byte:
+			body = v
+		case string:
+			body = []byte(v)
+		default:
+			http.Error(ctx.ResponseWriter, "cannot render "+offer, http.StatusInternalServerError)
+			return
+		}
+		ctx.ResponseWriter.Header().Set("Content-Type", offer)
+		ctx.Data(code, body)
+	}
+}
+
+// acceptQuality returns the q-value the most specific matching media range in specs gives
+// offer, or 0 if none matches.
+func acceptQuality(specs []acceptSpec, offer string) float64 {
+	q, specificity := 0.0, 0
+	for _, spec := range specs {
+		if !matchMediaType(spec.mediaType, offer) {
+			continue
+		}
+		s := 1
+		if spec.mediaType == offer {
+			s = 3
+		} else if spec.mediaType != "*/*" {
+			s = 2
+		}
+		if s > specificity {
+			q, specificity = spec.q, s
+		}
+	}
+	return q
+}
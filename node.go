@@ -1,13 +1,19 @@
 package restrum
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 // node represents a single node in the routing tree.
 type node struct {
-	pattern  string  // the route pattern to match, e.g., /p/:lang
-	part     string  // a part of the route, e.g., :lang
-	children []*node // child nodes, e.g., [doc, tutorial, intro]
-	isWild   bool    // whether the part contains a wildcard, e.g., :lang or *
+	pattern    string         // the route pattern to match, e.g., /p/:lang
+	part       string         // a part of the route, e.g., :lang
+	children   []*node        // child nodes, e.g., [doc, tutorial, intro]
+	isWild     bool           // whether the part contains a wildcard, e.g., :lang or *
+	catchAll   bool           // whether the part matches the rest of the path, e.g., *filepath
+	constraint *regexp.Regexp // the constraint a parameter must satisfy, e.g., (int) in :id(int)
 }
 
 // String returns a string representation of the node.
@@ -15,26 +21,112 @@ func (n *node) String() string {
 	return fmt.Sprintf("node{pattern=%s, part=%s, wild=%t}", n.pattern, n.part, n.isWild)
 }
 
+// syntax holds the marker characters that introduce parameter and catch-all parts.
+type syntax struct {
+	param    byte // e.g., ':' in :lang
+	wildcard byte // e.g., '*' in *filepath
+}
+
+// defaultSyntax is the colon and asterisk syntax used when no markers are configured.
+var defaultSyntax = syntax{param: ':', wildcard: '*'}
+
+// isParam reports whether part is a named parameter, e.g., :id or {id}.
+func (s syntax) isParam(part string) bool {
+	if isBraced(part) {
+		return !strings.HasSuffix(part, "...}")
+	}
+	return part[0] == s.param
+}
+
+// isCatchAll reports whether part is a catch-all parameter, e.g., *filepath or {filepath...}.
+func (s syntax) isCatchAll(part string) bool {
+	if isBraced(part) {
+		return strings.HasSuffix(part, "...}")
+	}
+	return part[0] == s.wildcard
+}
+
+// isBraced reports whether part is wrapped in braces, e.g., {id}.
+func isBraced(part string) bool {
+	return len(part) > 2 && part[0] == '{' && part[len(part)-1] == '}'
+}
+
+// paramName returns the name of a parameter or catch-all part. An unnamed part such as a
+// bare * is named after its marker.
+func paramName(part string) string {
+	if isBraced(part) {
+		return strings.TrimSuffix(part[1:len(part)-1], "...")
+	}
+	if i := strings.IndexByte(part, '('); i > 0 && strings.HasSuffix(part, ")") {
+		return part[1:i]
+	}
+	if len(part) == 1 {
+		return part
+	}
+	return part[1:]
+}
+
+// constraintAliases maps named constraints to the expressions they stand for.
+var constraintAliases = map[string]string{
+	"int": `-?[0-9]+`,
+}
+
+// paramConstraint compiles the constraint of a parameter part such as :id(int) or :name(\w+).
+// It returns nil when the part has no constraint and panics when the expression is invalid.
+func paramConstraint(part string) *regexp.Regexp {
+	i := strings.IndexByte(part, '(')
+	if i < 0 || !strings.HasSuffix(part, ")") {
+		return nil
+	}
+
+	expr := part[i+1 : len(part)-1]
+	if alias, ok := constraintAliases[expr]; ok {
+		expr = alias
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		panic("restrum: invalid constraint in " + part + ": " + err.Error())
+	}
+	return re
+}
+
+// accepts reports whether the request path part satisfies the node's constraint, if any.
+func (n *node) accepts(part string) bool {
+	return n.constraint == nil || n.constraint.MatchString(part)
+}
+
 // insert adds a new route pattern to the node.
-func (n *node) insert(pattern string, parts []string, height int) {
+func (n *node) insert(pattern string, parts []string, height int, s syntax) {
 	if len(parts) == height {
+		if n.pattern != "" && n.pattern != pattern {
+			panic(fmt.Sprintf("restrum: route %s conflicts with existing route %s", pattern, n.pattern))
+		}
 		n.pattern = pattern
 		return
 	}
 
 	part := parts[height]
-	child := n.matchChildren(part)
+	child := n.matchChildren(part, s.isParam(part) || s.isCatchAll(part))
+	if child != nil && child.isWild && child.part != part && (s.isParam(part) || s.isCatchAll(part)) {
+		panic(fmt.Sprintf("restrum: %s in route %s conflicts with existing wildcard %s", part, pattern, child.part))
+	}
 
 	if child == nil {
-		child = &node{part: part, isWild: part[0] == ':' || part[0] == '*'}
+		child = &node{part: part, isWild: s.isParam(part) || s.isCatchAll(part), catchAll: s.isCatchAll(part)}
+		if s.isParam(part) && !isBraced(part) {
+			child.constraint = paramConstraint(part)
+		}
 		n.children = append(n.children, child)
 	}
-	child.insert(pattern, parts, height+1)
+	child.insert(pattern, parts, height+1, s)
 }
 
-// search looks for a node that matches the given parts.
-func (n *node) search(parts []string, height int) *node {
-	if len(parts) == height || n.isWild {
+// search looks for a node that matches the given parts. Static children are tried before
+// wildcard children, so /user/new wins over /user/:id. A catch-all node ends the search
+// since it takes the rest of the path. With fold set, static parts are compared
+// case-insensitively.
+func (n *node) search(parts []string, height int, fold bool) *node {
+	if len(parts) == height || n.catchAll {
 		if n.pattern == "" {
 			return nil
 		}
@@ -43,8 +135,15 @@ func (n *node) search(parts []string, height int) *node {
 
 	part := parts[height]
 	for _, child := range n.children {
-		if child.part == part || child.isWild {
-			if result := child.search(parts, height+1); result != nil {
+		if !child.isWild && (child.part == part || fold && strings.EqualFold(child.part, part)) {
+			if result := child.search(parts, height+1, fold); result != nil {
+				return result
+			}
+		}
+	}
+	for _, child := range n.children {
+		if child.isWild && child.accepts(part) {
+			if result := child.search(parts, height+1, fold); result != nil {
 				return result
 			}
 		}
@@ -62,18 +161,20 @@ func (n *node) travel(list *[]*node) {
 	}
 }
 
-// matchChildren finds a child node that matches the given part.
-func (n *node) matchChildren(part string) *node {
+// matchChildren finds the child node for the given part. A wildcard part matches any
+// wildcard child, since only one may exist at each position.
+func (n *node) matchChildren(part string, wild bool) *node {
 	for _, child := range n.children {
-		if child.part == part || child.isWild {
+		if child.part == part || wild && child.isWild {
 			return child
 		}
 	}
 	return nil
 }
 
-// parsePattern splits a pattern into parts.
-func parsePattern(pattern string) []string {
+// parsePattern splits a pattern into parts. Everything from a wildcard marker at the start of
+// a part onwards becomes a single final part; a zero marker splits on slashes only.
+func parsePattern(pattern string, wildcard byte) []string {
 	var parts []string
 	start := 0
 	isWild := false
@@ -84,10 +185,7 @@ func parsePattern(pattern string) []string {
 				parts = append(parts, pattern[start:i])
 			}
 			start = i + 1
-		} else if pattern[i] == '*' {
-			if start != i {
-				parts = append(parts, pattern[start:i])
-			}
+		} else if wildcard != 0 && pattern[i] == wildcard && start == i {
 			parts = append(parts, pattern[i:])
 			isWild = true
 			break
@@ -0,0 +1,109 @@
+package restrum
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitSweepInterval is how often idle buckets are dropped from a rate limiter.
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitConfig configures the RateLimit middleware.
+type RateLimitConfig struct {
+	// Rate is the number of requests per second each client may make on average.
+	Rate int
+	// Burst is the number of requests a client may make at once. It defaults to Rate.
+	Burst int
+	// Key identifies the client a request counts against. It defaults to the client IP.
+	Key func(ctx *Context) string
+}
+
+// RateLimit creates a middleware that allows each client IP rps requests per second with
+// bursts of up to burst requests, and responds 429 with a Retry-After header beyond that.
+func RateLimit(rps, burst int) HandlerFunc {
+	return RateLimitWithConfig(RateLimitConfig{Rate: rps, Burst: burst})
+}
+
+// RateLimitWithConfig creates a token bucket rate limiting middleware with the given
+// configuration. It panics if config.Rate is not positive.
+func RateLimitWithConfig(config RateLimitConfig) HandlerFunc {
+	if config.Rate <= 0 {
+		panic("restrum: rate limit must be positive")
+	}
+	if config.Burst <= 0 {
+		config.Burst = config.Rate
+	}
+	key := config.Key
+	if key == nil {
+		key = (*Context).ClientIP
+	}
+	limiter := &rateLimiter{
+		rate:    float64(config.Rate),
+		burst:   float64(config.Burst),
+		buckets: make(map[string]*bucket),
+	}
+
+	return func(ctx *Context) {
+		if wait, ok := limiter.allow(key(ctx), time.Now()); !ok {
+			ctx.ResponseWriter.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(ctx.ResponseWriter, "TOO MANY REQUESTS", http.StatusTooManyRequests)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// bucket holds the tokens left for one client as of last.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter tracks a token bucket per client key.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// allow takes a token from the bucket for key, or reports how long until one is available.
+func (l *rateLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// sweep drops the buckets that have refilled completely, since a new bucket for the same
+// client would be identical.
+func (l *rateLimiter) sweep(now time.Time) {
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,38 @@
+package restrum
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recovery creates a middleware that recovers from panics further down the chain, logs the
+// stack trace, and responds 500 if nothing has been written yet.
+func Recovery() HandlerFunc {
+	return RecoveryWithHandler(func(ctx *Context, _ interface{}) {
+		if !ctx.Written() {
+			http.Error(ctx.ResponseWriter, "INTERNAL SERVER ERROR", http.StatusInternalServerError)
+		}
+	})
+}
+
+// RecoveryWithHandler creates a recovery middleware that logs the stack trace and passes
+// the recovered value to handle, which is responsible for writing the response. A panic
+// after the client disconnected, or carrying an error IsClientGone accepts, is logged as a
+// single "client gone" line instead and handle is not called, since there is nobody left
+// to answer.
+func RecoveryWithHandler(handle func(ctx *Context, recovered interface{})) HandlerFunc {
+	return func(ctx *Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if err, ok := recovered.(error); ok && IsClientGone(err) || ctx.ClientGone() {
+					log.Printf("restrum: %s %s: client gone: %v", ctx.HTTPMethod, ctx.RoutePath, recovered)
+					return
+				}
+				log.Printf("panic recovered: %v\n%s", recovered, debug.Stack())
+				handle(ctx, recovered)
+			}
+		}()
+		ctx.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package restrum
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+)
+
+const (
+	// requestIDHeader carries the request ID in both directions.
+	requestIDHeader = "X-Request-ID"
+	// requestIDKey is the context key the request ID is stored under.
+	requestIDKey = "requestID"
+	// maxRequestIDLength bounds the length of an incoming request ID that is reused.
+	maxRequestIDLength = 128
+)
+
+// RequestID creates a middleware that assigns each request an ID, reusing the incoming
+// X-Request-ID header when present or generating a random one otherwise. The ID is sent
+// back in the X-Request-ID response header and stored under the "requestID" key, where
+// Context.Logger picks it up.
+func RequestID() HandlerFunc {
+	return func(ctx *Context) {
+		id := ctx.Request.Header.Get(requestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength || !printable(id) {
+			id = newRequestID()
+		}
+		ctx.SetHeader(requestIDHeader, id)
+		ctx.Set(requestIDKey, id)
+		ctx.Next()
+	}
+}
+
+// Logger returns a logger whose lines are prefixed with the request ID set by the
+// RequestID middleware. Without a request ID it returns the standard logger.
+func (ctx *Context) Logger() *log.Logger {
+	id := ctx.GetString(requestIDKey)
+	if id == "" {
+		return log.Default()
+	}
+	if ctx.logger == nil || ctx.logger.Prefix() != "["+id+"] " {
+		std := log.Default()
+		ctx.logger = log.New(std.Writer(), "["+id+"] ", std.Flags()|log.Lmsgprefix)
+	}
+	return ctx.logger
+}
+
+// newRequestID returns a random 128-bit ID in hex.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("restrum: generating request ID: " + err.Error())
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// printable reports whether s only contains printable ASCII characters.
+func printable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package restrum
+
+import (
+	"bufio"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// IsClientGone reports whether err is a write error caused by the client disconnecting,
+// such as a broken pipe or a connection reset, rather than by the server.
+func IsClientGone(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, net.ErrClosed)
+}
+
+// responseWriter wraps the http.ResponseWriter of a request to record the status code and
+// body size that reached the client.
+type responseWriter struct {
+	http.ResponseWriter
+	ctx         *Context
+	size        int
+	wroteHeader bool
+	// discardBody drops the body while still sending the header, so a GET handler can
+	// answer a HEAD request.
+	discardBody bool
+	// clientGone is set once a write fails because the client disconnected.
+	clientGone bool
+	// reportPhases marks the writer that reaches the client, which adds the Server-Timing
+	// entries of Config.Debug when it sends the header.
+	reportPhases bool
+}
+
+// WriteHeader sends the status code and records it in the context's ResponseCode. Calls
+// after the header has been sent are ignored with a warning. Informational 1xx codes other
+// than 101 may be sent any number of times before the final status.
+func (w *responseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		if code != w.ctx.ResponseCode {
+			log.Printf("restrum: %s %s: status %d ignored, %d already sent", w.ctx.HTTPMethod, w.ctx.RoutePath, code, w.ctx.ResponseCode)
+		}
+		return
+	}
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.wroteHeader = true
+	w.ctx.ResponseCode = code
+	if w.reportPhases && w.ctx.config.Debug {
+		w.ctx.addPhaseTimings(w.Header())
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write sends data, writing a 200 header first if none has been sent. With discardBody
+// set the data is reported as written but not sent.
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.discardBody {
+		return len(data), nil
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	if err != nil && IsClientGone(err) {
+		w.clientGone = true
+	}
+	return n, err
+}
+
+// Flush sends any buffered data to the client, writing a 200 header first if none has been sent.
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, as for a WebSocket upgrade.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,72 @@
+package restrum
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// goneWriter is a ResponseWriter whose client has disconnected.
+type goneWriter struct {
+	*httptest.ResponseRecorder
+}
+
+// Write fails with the error a write to a closed connection returns.
+func (w goneWriter) Write([]byte) (int, error) {
+	return 0, &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+}
+
+func TestIsClientGone(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)}, true},
+		{&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.ECONNRESET)}, true},
+		{net.ErrClosed, true},
+		{errors.New("disk full"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsClientGone(tt.err); got != tt.want {
+			t.Errorf("IsClientGone(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestClientGoneIsNotAServerError(t *testing.T) {
+	var access, std bytes.Buffer
+	log.SetOutput(&std)
+	defer log.SetOutput(os.Stderr)
+
+	handled := false
+	e := New()
+	e.Use(
+		LoggerWithConfig(LoggerConfig{Output: &access, Format: "{method} {path} {status}"}),
+		RecoveryWithHandler(func(ctx *Context, _ interface{}) { handled = true }),
+	)
+	e.GET("/stream", func(ctx *Context) {
+		ctx.Status(http.StatusOK)
+		if _, err := ctx.ResponseWriter.Write([]byte("chunk")); err != nil {
+			panic(err)
+		}
+	})
+
+	e.ServeHTTP(goneWriter{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if handled {
+		t.Error("recovery handler ran for a disconnected client")
+	}
+	if !strings.Contains(std.String(), "client gone") || strings.Contains(std.String(), "panic recovered") {
+		t.Errorf("recovery logged %q, want a client gone line without a stack trace", std.String())
+	}
+	if !strings.HasSuffix(access.String(), "GET /stream 499\n") {
+		t.Errorf("access log %q, want it to end in %q", access.String(), "GET /stream 499\n")
+	}
+}
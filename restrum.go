@@ -1,10 +1,22 @@
 package restrum
 
 import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html/template"
 	"log"
+	"mime"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // HandlerFunc defines the handler used by middleware as return value.
@@ -13,6 +25,7 @@ type HandlerFunc func(*Context)
 // RouterGroup represents a group of routes with a common prefix and middleware.
 type RouterGroup struct {
 	prefix      string
+	host        string
 	middlewares []HandlerFunc
 	parent      *RouterGroup
 	engine      *Engine
@@ -21,9 +34,18 @@ type RouterGroup struct {
 // Engine is the main struct of the framework. It contains the router and configuration.
 type Engine struct {
 	*RouterGroup
-	router *router
-	groups []*RouterGroup
-	config Config
+	router  *router
+	hosts   map[string]*router
+	hostsMu sync.Mutex
+	config  Config
+
+	server   *http.Server
+	serverMu sync.Mutex
+
+	templates *template.Template
+	noRoute   HandlerFunc
+	noMethod  HandlerFunc
+	metrics   metricsRegistry
 }
 
 // Config holds the configuration for the Engine.
@@ -31,39 +53,168 @@ type Config struct {
 	AllowOrigins     []string
 	AllowMethods     []string
 	AllowCredentials bool
+	// AllowHeaders lists the request headers allowed in preflight responses; "*" reflects
+	// the headers the browser asks for.
+	AllowHeaders []string
+	// ExposeHeaders lists the response headers browser scripts may read.
+	ExposeHeaders []string
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+
+	// ParamMarker and WildcardMarker introduce named parameter and catch-all parts in
+	// route patterns, e.g., :id and *filepath. They default to ':' and '*'. Brace parts
+	// such as {id} and {filepath...} are always recognized as well.
+	ParamMarker    byte
+	WildcardMarker byte
+
+	// DisableTrailingSlashRedirect serves a request whose path differs from the matched
+	// route only by a trailing slash as it is. By default it is redirected to the
+	// registered form.
+	DisableTrailingSlashRedirect bool
+	// RedirectFixedPath retries a missed lookup with static path segments compared
+	// case-insensitively and redirects to the registered casing when that matches.
+	// Captured parameter values keep their original case.
+	RedirectFixedPath bool
+	// DisableAutoOPTIONS stops OPTIONS requests for paths with routes under other methods
+	// from being answered automatically. By default they get a 204 listing those methods in
+	// the Allow header, after running the middleware of the group that owns a matching route
+	// so a CORS middleware can answer preflight requests.
+	DisableAutoOPTIONS bool
+
+	// TrustedProxies lists proxy addresses or CIDR ranges whose RemoteIPHeaders are
+	// trusted by Context.ClientIP. Empty trusts no proxy.
+	TrustedProxies []string
+	// RemoteIPHeaders lists, in order, the headers Context.ClientIP reads the client address
+	// from when the peer is a trusted proxy. Nil means X-Forwarded-For, then X-Real-IP.
+	RemoteIPHeaders []string
+
+	// TLSConfig overrides the TLS settings used by RunTLS, e.g., to set cipher suites or
+	// load certificates from memory.
+	TLSConfig *tls.Config
+
+	// Debug enables development-only behaviour such as the DebugDump middleware and
+	// Server-Timing entries reporting how long each middleware and handler took.
+	Debug bool
+
+	// MaxJSONDepth limits how deeply objects and arrays may nest in a JSON body. Zero means no limit.
+	MaxJSONDepth int
+	// MaxJSONElements limits the number of keys or elements in any JSON object or array. Zero means no limit.
+	MaxJSONElements int
+
+	// MaxMultipartMemory is how many bytes of a multipart body are kept in memory while
+	// parsing; the rest spills to temporary files. Zero means 32 MB.
+	MaxMultipartMemory int64
+	// MaxBodySize limits the size of every request body, as BodyLimit does. Zero means no limit.
+	MaxBodySize int64
+
+	// ServerHeader, when set, is sent as the Server response header. net/http never adds a
+	// Server header itself, so leaving it empty sends none unless a handler sets one.
+	ServerHeader string
+	// DisableDateHeader stops net/http from adding its automatic Date response header.
+	// Headers such as Content-Length and Transfer-Encoding are managed by net/http and
+	// cannot be suppressed this way.
+	DisableDateHeader bool
+}
+
+// DefaultConfig returns the configuration used by New when none is given.
+func DefaultConfig() Config {
+	return Config{
+		MaxMultipartMemory: defaultMultipartMemory,
+	}
 }
 
 // New creates a new Engine instance with optional configuration.
+// Without one, DefaultConfig is used.
 func New(cfg ...Config) *Engine {
-	var config Config
+	config := DefaultConfig()
 	if len(cfg) > 0 {
 		config = cfg[0]
 	}
 
 	engine := &Engine{
-		router: NewRouter(),
 		config: config,
 	}
+	engine.router = engine.newRouter()
 	engine.RouterGroup = &RouterGroup{
 		engine: engine,
 	}
-	engine.groups = []*RouterGroup{
-		engine.RouterGroup,
-	}
 	return engine
 }
 
 // Group creates a new RouterGroup with the given prefix.
 func (e *RouterGroup) Group(prefix string) *RouterGroup {
-	engine := e.engine
-	newGroup := &RouterGroup{
+	return &RouterGroup{
 		prefix: e.prefix + prefix,
+		host:   e.host,
 		parent: e,
-		engine: engine,
+		engine: e.engine,
+	}
+}
+
+// Host creates a RouterGroup whose routes only match requests for the given host name.
+// Requests for a host with no route for the path, under any method, fall back to the
+// routes registered without a host. An empty host or "*" returns a group for those default routes.
+func (e *Engine) Host(host string) *RouterGroup {
+	host = strings.ToLower(host)
+	if host == "*" {
+		host = ""
+	}
+	return &RouterGroup{
+		host:   host,
+		parent: e.RouterGroup,
+		engine: e,
+	}
+}
+
+// newRouter creates a router using the pattern markers from the engine's configuration.
+func (e *Engine) newRouter() *router {
+	r := NewRouter()
+	if e.config.ParamMarker != 0 {
+		r.syntax.param = e.config.ParamMarker
+	}
+	if e.config.WildcardMarker != 0 {
+		r.syntax.wildcard = e.config.WildcardMarker
 	}
+	return r
+}
 
-	engine.groups = append(engine.groups, newGroup)
-	return newGroup
+// hostRouter returns the router for the given host, creating it if needed.
+func (e *Engine) hostRouter(host string) *router {
+	if host == "" {
+		return e.router
+	}
+
+	e.hostsMu.Lock()
+	defer e.hostsMu.Unlock()
+	if e.hosts == nil {
+		e.hosts = make(map[string]*router)
+	}
+	r, ok := e.hosts[host]
+	if !ok {
+		r = e.newRouter()
+		e.hosts[host] = r
+	}
+	return r
+}
+
+// routerFor selects the router that should serve the request of ctx: the router for its
+// host when it has a route for the path under any method, so a wrong method gets that
+// host's 405, otherwise the default router.
+func (e *Engine) routerFor(ctx *Context) *router {
+	if len(e.hosts) == 0 {
+		return e.router
+	}
+
+	host := ctx.Request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if r, ok := e.hosts[strings.ToLower(host)]; ok {
+		if len(r.allowed(ctx)) > 0 {
+			return r
+		}
+	}
+	return e.router
 }
 
 // Use adds middleware to the RouterGroup.
@@ -71,74 +222,260 @@ func (e *RouterGroup) Use(middlewares ...HandlerFunc) {
 	e.middlewares = append(e.middlewares, middlewares...)
 }
 
-// AddRoutes adds a route to the router with the given method, pattern, and handler.
-func (e *RouterGroup) AddRoutes(method string, comp string, handler HandlerFunc) {
+// chain returns the middleware of the group and its ancestors, ordered from the root group down.
+func (e *RouterGroup) chain() []HandlerFunc {
+	if e.parent == nil {
+		// Clip so callers appending to the chain never write into the group's own slice.
+		return slices.Clip(e.middlewares)
+	}
+	parent := e.parent.chain()
+	middlewares := make([]HandlerFunc, 0, len(parent)+len(e.middlewares))
+	middlewares = append(middlewares, parent...)
+	return append(middlewares, e.middlewares...)
+}
+
+// AddRoutes adds a route to the router with the given method, pattern, and handlers.
+// The last handler handles the request; any before it are middleware for this route only,
+// run after the group middleware.
+func (e *RouterGroup) AddRoutes(method string, comp string, handlers ...HandlerFunc) {
 	pattern := e.prefix + comp
-	e.engine.router.AddRoutes(method, pattern, handler)
+	e.engine.hostRouter(e.host).AddRoutes(e, method, pattern, handlers...)
+}
+
+// Alias registers newPattern under the group with the handler already registered for
+// method and the full existingPattern. The alias uses this group's middleware, not that of
+// the original route. It panics if no such route exists.
+func (e *RouterGroup) Alias(method, newPattern, existingPattern string) {
+	rt := e.engine.hostRouter(e.host).lookup(method, existingPattern)
+	if rt == nil {
+		panic("restrum: cannot alias unknown route " + method + " " + existingPattern)
+	}
+	e.AddRoutes(method, newPattern, rt.handler)
 }
 
 // GET adds a GET route to the router.
-func (e *RouterGroup) GET(pattern string, handler HandlerFunc) {
-	e.AddRoutes("GET", pattern, handler)
+func (e *RouterGroup) GET(pattern string, handlers ...HandlerFunc) {
+	e.AddRoutes("GET", pattern, handlers...)
 }
 
 // POST adds a POST route to the router.
-func (e *RouterGroup) POST(pattern string, handler HandlerFunc) {
-	e.AddRoutes("POST", pattern, handler)
+func (e *RouterGroup) POST(pattern string, handlers ...HandlerFunc) {
+	e.AddRoutes("POST", pattern, handlers...)
 }
 
 // PUT adds a PUT route to the router.
-func (e *RouterGroup) PUT(pattern string, handler HandlerFunc) {
-	e.AddRoutes("PUT", pattern, handler)
+func (e *RouterGroup) PUT(pattern string, handlers ...HandlerFunc) {
+	e.AddRoutes("PUT", pattern, handlers...)
 }
 
 // DELETE adds a DELETE route to the router.
-func (e *RouterGroup) DELETE(pattern string, handler HandlerFunc) {
-	e.AddRoutes("DELETE", pattern, handler)
+func (e *RouterGroup) DELETE(pattern string, handlers ...HandlerFunc) {
+	e.AddRoutes("DELETE", pattern, handlers...)
 }
 
-// OPTION adds an OPTION route to the router.
+// PATCH adds a PATCH route to the router.
+func (e *RouterGroup) PATCH(pattern string, handlers ...HandlerFunc) {
+	e.AddRoutes("PATCH", pattern, handlers...)
+}
+
+// HEAD adds a HEAD route to the router. Paths with a GET route but no HEAD route answer
+// HEAD requests with the GET handler and the body discarded.
+func (e *RouterGroup) HEAD(pattern string, handlers ...HandlerFunc) {
+	e.AddRoutes("HEAD", pattern, handlers...)
+}
+
+// OPTIONS adds an OPTIONS route to the router.
+func (e *RouterGroup) OPTIONS(pattern string, handlers ...HandlerFunc) {
+	e.AddRoutes("OPTIONS", pattern, handlers...)
+}
+
+// OPTION adds an OPTIONS route to the router.
+//
+// Deprecated: Use OPTIONS, which also applies the group prefix and middleware.
 func (e *Engine) OPTION(pattern string, handler HandlerFunc) {
-	e.router.AddRoutes("OPTION", pattern, handler)
+	e.OPTIONS(pattern, handler)
 }
 
-// Run starts the HTTP server on the specified address.
-func (e *Engine) Run(addr string) (err error) {
-	if isPortInUse(addr) {
-		panic("port was used!")
+// Routes returns the registered routes, including those limited to a host, sorted by host,
+// path, and method.
+func (e *Engine) Routes() []RouteInfo {
+	routes := e.router.infos("")
+	e.hostsMu.Lock()
+	for host, r := range e.hosts {
+		routes = append(routes, r.infos(host)...)
 	}
+	e.hostsMu.Unlock()
 
-	log.Printf("http server running on %s", addr)
-	return http.ListenAndServe(addr, e)
+	slices.SortFunc(routes, func(a, b RouteInfo) int {
+		return cmp.Or(
+			strings.Compare(a.Host, b.Host),
+			strings.Compare(a.Path, b.Path),
+			strings.Compare(a.Method, b.Method),
+		)
+	})
+	return routes
+}
 
+// NoRoute sets the handler used when no route matches the request path under any method.
+// Without one, a plain-text 404 is sent.
+func (e *Engine) NoRoute(handler HandlerFunc) {
+	e.noRoute = handler
 }
 
-// ServeHTTP implements the http.Handler interface to handle HTTP requests.
-func (e *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	var middlewares []HandlerFunc
-	for _, group := range e.groups {
-		if strings.HasPrefix(req.URL.Path, group.prefix) {
-			middlewares = append(middlewares, group.middlewares...)
+// NoMethod sets the handler used when the request path has routes under other methods
+// only. The Allow header is already set when it runs, and it should send the 405 itself.
+// Without one, a plain-text 405 is sent.
+func (e *Engine) NoMethod(handler HandlerFunc) {
+	e.noMethod = handler
+}
+
+// LoadHTMLGlob parses the templates matching pattern once for use by Context.RenderHTML.
+func (e *Engine) LoadHTMLGlob(pattern string) error {
+	tmpl, err := template.ParseGlob(pattern)
+	if err != nil {
+		return err
+	}
+	e.templates = tmpl
+	return nil
+}
+
+// LoadHTMLFiles parses the given template files once for use by Context.RenderHTML.
+func (e *Engine) LoadHTMLFiles(files ...string) error {
+	tmpl, err := template.ParseFiles(files...)
+	if err != nil {
+		return err
+	}
+	e.templates = tmpl
+	return nil
+}
+
+// SetFavicon loads the file at path once and serves it at /favicon.ico with long-lived cache headers.
+func (e *Engine) SetFavicon(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	e.GET("/favicon.ico", func(ctx *Context) {
+		ctx.ResponseWriter.Header().Set("Content-Type", contentType)
+		ctx.ResponseWriter.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		ctx.Data(http.StatusOK, data)
+	})
+	return nil
+}
+
+// NoFavicon answers /favicon.ico with an empty 204 response instead of a 404.
+func (e *Engine) NoFavicon() {
+	e.GET("/favicon.ico", func(ctx *Context) {
+		ctx.ResponseWriter.Header().Set("Cache-Control", "public, max-age=86400")
+		ctx.Data(http.StatusNoContent, nil)
+	})
+}
+
+// HealthCheck registers a GET route at path, or /healthz when path is empty, for health
+// probes. It responds 200 "OK" when check is nil or returns nil, and 503 with the error
+// message otherwise.
+func (e *Engine) HealthCheck(path string, check func() error) {
+	if path == "" {
+		path = "/healthz"
+	}
+	e.GET(path, func(ctx *Context) {
+		ctx.ResponseWriter.Header().Set("Cache-Control", "no-store")
+		if check != nil {
+			if err := check(); err != nil {
+				ctx.String(http.StatusServiceUnavailable, err.Error())
+				return
+			}
 		}
+		ctx.String(http.StatusOK, "OK")
+	})
+}
+
+// Run starts the HTTP server on the specified address.
+// It returns an error if the address cannot be bound, e.g., because the port is in use.
+func (e *Engine) Run(addr string) (err error) {
+	ln, err := listen(addr)
+	if err != nil {
+		return err
 	}
 
-	ctx := newContext(w, req, &e.config)
-	ctx.middleware = middlewares
-	cfg := &handlerCfg{ctx}
-	e.router.handle(cfg)
+	log.Printf("http server running on %s", addr)
+	return e.newServer(addr).Serve(ln)
 }
 
-// isPortInUse checks if the specified port is already in use.
-func isPortInUse(port string) bool {
-	ln, err := net.Listen("tcp", port)
+// RunTLS starts the HTTPS server on the specified address using the given certificate and key files.
+// Config.TLSConfig, when set, is used for the server; certFile and keyFile may then be empty
+// if it already provides certificates.
+func (e *Engine) RunTLS(addr, certFile, keyFile string) (err error) {
+	ln, err := listen(addr)
 	if err != nil {
-		return true
+		return err
+	}
+
+	server := e.newServer(addr)
+	server.TLSConfig = e.config.TLSConfig
+
+	log.Printf("https server running on %s", addr)
+	return server.ServeTLS(ln, certFile, keyFile)
+}
+
+// listen binds the TCP address, defaulting to ":http" like net/http when addr is empty.
+func listen(addr string) (net.Listener, error) {
+	if addr == "" {
+		addr = ":http"
 	}
-	err = ln.Close()
+	ln, err := net.Listen("tcp", addr)
 	if err != nil {
-		return false
+		return nil, fmt.Errorf("restrum: listen on %s: %w", addr, err)
+	}
+	return ln, nil
+}
+
+// newServer creates the http.Server for addr and keeps it for Shutdown.
+func (e *Engine) newServer(addr string) *http.Server {
+	e.serverMu.Lock()
+	defer e.serverMu.Unlock()
+	e.server = &http.Server{Addr: addr, Handler: e}
+	return e.server
+}
+
+// Shutdown gracefully stops the server started by Run, waiting for in-flight requests
+// to finish until ctx is done. It does nothing if the server is not running.
+func (e *Engine) Shutdown(ctx context.Context) error {
+	e.serverMu.Lock()
+	server := e.server
+	e.serverMu.Unlock()
+
+	if server == nil {
+		return nil
 	}
-	return false
+	return server.Shutdown(ctx)
+}
+
+// ServeHTTP implements the http.Handler interface to handle HTTP requests.
+// Only the middleware of the group that registered the matched route, and of its
+// ancestors, is applied to the request.
+func (e *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if e.config.ServerHeader != "" {
+		w.Header().Set("Server", e.config.ServerHeader)
+	}
+	if e.config.DisableDateHeader {
+		// A nil value keeps net/http from filling in the Date header.
+		w.Header()["Date"] = nil
+	}
+
+	ctx := newContext(w, req, e)
+	defer ctx.runDeferred()
+	if e.config.MaxBodySize > 0 && !limitBody(ctx, e.config.MaxBodySize) {
+		return
+	}
+	cfg := &handlerCfg{ctx}
+	e.routerFor(ctx).handle(cfg)
 }
 
 // CORSMiddleware creates a middleware to handle CORS requests.
@@ -154,6 +491,12 @@ func CORSMiddleware(config *Config) HandlerFunc {
 			}
 		}
 
+		// Browsers reject a literal "*" origin on credentialed requests, so echo the origin instead.
+		if allowedOrigin == "*" && config.AllowCredentials {
+			allowedOrigin = origin
+			ctx.ResponseWriter.Header().Add("Vary", "Origin")
+		}
+
 		if allowedOrigin != "" {
 			ctx.ResponseWriter.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
 		}
@@ -166,8 +509,24 @@ func CORSMiddleware(config *Config) HandlerFunc {
 			ctx.ResponseWriter.Header().Set("Access-Control-Allow-Credentials", "true")
 		}
 
+		if len(config.ExposeHeaders) > 0 {
+			ctx.ResponseWriter.Header().Set("Access-Control-Expose-Headers", joinStrings(config.ExposeHeaders, ", "))
+		}
+
 		if ctx.Request.Method == "OPTIONS" {
-			ctx.ResponseWriter.WriteHeader(http.StatusOK)
+			if slices.Contains(config.AllowHeaders, "*") {
+				if requested := ctx.Request.Header.Get("Access-Control-Request-Headers"); requested != "" {
+					ctx.ResponseWriter.Header().Set("Access-Control-Allow-Headers", requested)
+				}
+			} else if len(config.AllowHeaders) > 0 {
+				ctx.ResponseWriter.Header().Set("Access-Control-Allow-Headers", joinStrings(config.AllowHeaders, ", "))
+			}
+
+			if config.MaxAge > 0 {
+				ctx.ResponseWriter.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge/time.Second)))
+			}
+
+			ctx.Status(http.StatusOK)
 			return
 		}
 		ctx.Next()
@@ -0,0 +1,139 @@
+package restrum
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// record returns a middleware that appends name to trace and continues the chain.
+func record(trace *[]string, name string) HandlerFunc {
+	return func(ctx *Context) {
+		*trace = append(*trace, name)
+		ctx.Next()
+	}
+}
+
+func TestGroupMiddlewareBelongsToOwningGroup(t *testing.T) {
+	var trace []string
+	e := New()
+	first := e.Group("/api")
+	first.Use(record(&trace, "first"))
+	second := e.Group("/api")
+	second.Use(record(&trace, "second"))
+
+	first.GET("/a", func(ctx *Context) { trace = append(trace, "a") })
+	second.GET("/b", func(ctx *Context) { trace = append(trace, "b") })
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/api/a", []string{"first", "a"}},
+		{"/api/b", []string{"second", "b"}},
+	}
+	for _, tt := range tests {
+		trace = nil
+		if w := perform(e, http.MethodGet, tt.target, nil); w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", tt.target, w.Code)
+		}
+		if !reflect.DeepEqual(trace, tt.want) {
+			t.Errorf("%s: ran %v, want %v", tt.target, trace, tt.want)
+		}
+	}
+}
+
+func TestNestedGroupMiddlewareRunsOnceInOrder(t *testing.T) {
+	var trace []string
+	e := New()
+	e.Use(record(&trace, "root"))
+	api := e.Group("/api")
+	api.Use(record(&trace, "api"))
+	v1 := api.Group("/v1")
+	v1.Use(record(&trace, "v1"))
+
+	api.GET("/x", func(ctx *Context) { trace = append(trace, "api handler") })
+	v1.GET("/x", record(&trace, "route"), func(ctx *Context) { trace = append(trace, "v1 handler") })
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/api/v1/x", []string{"root", "api", "v1", "route", "v1 handler"}},
+		{"/api/x", []string{"root", "api", "api handler"}},
+	}
+	for _, tt := range tests {
+		trace = nil
+		if w := perform(e, http.MethodGet, tt.target, nil); w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", tt.target, w.Code)
+		}
+		if !reflect.DeepEqual(trace, tt.want) {
+			t.Errorf("%s: ran %v, want %v", tt.target, trace, tt.want)
+		}
+	}
+}
+
+func TestCORSWildcardOrigin(t *testing.T) {
+	tests := []struct {
+		credentials bool
+		origin      string
+		vary        string
+	}{
+		{true, "https://app.example", "Origin"},
+		{false, "*", ""},
+	}
+	for _, tt := range tests {
+		e := New()
+		e.Use(CORSMiddleware(&Config{AllowOrigins: []string{"*"}, AllowCredentials: tt.credentials}))
+		e.GET("/", func(ctx *Context) { ctx.NoContent() })
+
+		w := perform(e, http.MethodGet, "/", nil, "Origin", "https://app.example")
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.origin {
+			t.Errorf("credentials %v: Access-Control-Allow-Origin = %q, want %q", tt.credentials, got, tt.origin)
+		}
+		if got := w.Header().Get("Vary"); got != tt.vary {
+			t.Errorf("credentials %v: Vary = %q, want %q", tt.credentials, got, tt.vary)
+		}
+	}
+}
+
+func TestConcurrentRouteRegistration(t *testing.T) {
+	const n = 50
+	e := New()
+	hosts := []string{"", "a.example", "b.example"}
+
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(host string, i int) {
+				defer wg.Done()
+				group := e.RouterGroup
+				if host != "" {
+					group = e.Host(host)
+				}
+				path := "/r" + strconv.Itoa(i)
+				group.GET(path, func(ctx *Context) { ctx.String(http.StatusOK, host+path) })
+			}(host, i)
+		}
+	}
+	wg.Wait()
+
+	for _, host := range hosts {
+		for i := 0; i < n; i++ {
+			path := "/r" + strconv.Itoa(i)
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if host != "" {
+				req.Host = host
+			}
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, req)
+			if w.Code != http.StatusOK || w.Body.String() != host+path {
+				t.Errorf("%s%s: got %d %q", host, path, w.Code, w.Body.String())
+			}
+		}
+	}
+}
@@ -1,11 +1,48 @@
 package restrum
 
-import "net/http"
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
 
 // router represents the routing tree and handlers.
+// Registration is guarded by mu so routes may be added from several goroutines at startup;
+// lookups are not locked and must not run concurrently with registration.
 type router struct {
-	root     map[string]*node
-	handlers map[string]HandlerFunc
+	mu     sync.Mutex
+	root   map[string]*node
+	routes map[string]*route
+	syntax syntax
+}
+
+// route holds a registered handler, its route-level middleware, and the group that owns it.
+type route struct {
+	handler     HandlerFunc
+	middlewares []HandlerFunc
+	group       *RouterGroup
+}
+
+// chain returns the group middleware, then the route's own middleware, then the handler.
+func (rt *route) chain() []HandlerFunc {
+	groupChain := rt.group.chain()
+	chain := make([]HandlerFunc, 0, len(groupChain)+len(rt.middlewares)+1)
+	chain = append(chain, groupChain...)
+	chain = append(chain, rt.middlewares...)
+	return append(chain, rt.handler)
+}
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Method string
+	Path   string
+	// Host is the host the route is limited to, or empty for the default routes.
+	Host string
+	// Handler is the name of the function that handles the route.
+	Handler string
 }
 
 // handlerCfg holds the context for the handler.
@@ -16,42 +53,99 @@ type handlerCfg struct {
 // NewRouter creates a new router instance.
 func NewRouter() *router {
 	return &router{
-		handlers: make(map[string]HandlerFunc),
-		root:     make(map[string]*node),
+		routes: make(map[string]*route),
+		root:   make(map[string]*node),
+		syntax: defaultSyntax,
 	}
 }
 
-// AddRoutes adds a route to the router with the given method, pattern, and handler.
-func (r *router) AddRoutes(method, pattern string, handler HandlerFunc) {
-	parts := parsePattern(pattern)
+// AddRoutes adds a route owned by group to the router with the given method, pattern, and handlers.
+// The last handler handles the request and the preceding ones are route-level middleware.
+// It panics if the route is already registered, conflicts with an existing wildcard, or
+// has a catch-all before its last segment.
+func (r *router) AddRoutes(group *RouterGroup, method, pattern string, handlers ...HandlerFunc) {
+	if len(handlers) == 0 {
+		panic("restrum: no handler for route " + method + " " + pattern)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parts := parsePattern(pattern, r.syntax.wildcard)
+	for _, part := range parts[:max(len(parts)-1, 0)] {
+		if r.syntax.isCatchAll(part) {
+			panic("restrum: catch-all " + part + " must be the last segment of route " + pattern)
+		}
+	}
 	key := method + "_" + pattern
+	if _, ok := r.routes[key]; ok {
+		panic("restrum: route " + method + " " + pattern + " is already registered")
+	}
 
 	if _, ok := r.root[method]; !ok {
 		r.root[method] = &node{}
 	}
 
-	r.root[method].insert(pattern, parts, 0)
-	r.handlers[key] = handler
+	r.root[method].insert(pattern, parts, 0, r.syntax)
+	r.routes[key] = &route{
+		handler:     handlers[len(handlers)-1],
+		middlewares: handlers[:len(handlers)-1],
+		group:       group,
+	}
+}
+
+// lookup returns the route registered for the exact method and pattern, or nil.
+func (r *router) lookup(method, pattern string) *route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.routes[method+"_"+pattern]
+}
+
+// infos returns a RouteInfo, tagged with host, for every route registered in the router.
+func (r *router) infos(host string) []RouteInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var infos []RouteInfo
+	for method, root := range r.root {
+		var nodes []*node
+		root.travel(&nodes)
+		for _, n := range nodes {
+			rt := r.routes[method+"_"+n.pattern]
+			infos = append(infos, RouteInfo{
+				Method:  method,
+				Path:    n.pattern,
+				Host:    host,
+				Handler: handlerName(rt.handler),
+			})
+		}
+	}
+	return infos
+}
+
+// handlerName returns the name of the function h, e.g., github.com/user/app.listUsers.
+func handlerName(h HandlerFunc) string {
+	return runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
 }
 
-// getRoute retrieves the node and parameters for the given method and path.
-func (r *router) getRoute(method, path string) (*node, map[string]string) {
-	searchParts := parsePattern(path)
-	params := make(map[string]string)
+// getRoute retrieves the node and parameters, in pattern order, for the given method and path.
+func (r *router) getRoute(method, path string) (*node, []Param) {
+	searchParts := parsePattern(path, 0)
 	root, ok := r.root[method]
 
 	if !ok {
 		return nil, nil
 	}
 
-	n := root.search(searchParts, 0)
+	n := root.search(searchParts, 0, false)
 	if n != nil {
-		parts := parsePattern(n.pattern)
+		var params []Param
+		parts := parsePattern(n.pattern, r.syntax.wildcard)
 		for i, part := range parts {
-			if part[0] == ':' {
-				params[part[1:]] = searchParts[i]
-			} else if part[0] == '*' {
-				params[part[1:]] = joinParts(searchParts[i:])
+			if r.syntax.isParam(part) {
+				params = append(params, Param{Key: paramName(part), Value: searchParts[i]})
+			} else if r.syntax.isCatchAll(part) {
+				params = append(params, Param{Key: paramName(part), Value: remainingPath(path, i)})
 				break
 			}
 		}
@@ -60,33 +154,205 @@ func (r *router) getRoute(method, path string) (*node, map[string]string) {
 	return nil, nil
 }
 
+// find matches path under method. A HEAD request without its own route falls back to the
+// GET route for the path; the method the match was found under is returned.
+func (r *router) find(method, path string) (*node, []Param, string) {
+	n, params := r.getRoute(method, path)
+	if n == nil && method == http.MethodHead {
+		n, params = r.getRoute(http.MethodGet, path)
+		method = http.MethodGet
+	}
+	return n, params, method
+}
+
 // handle processes the request and executes the corresponding handler.
 func (r *router) handle(ctx *handlerCfg) {
-	n, params := r.getRoute(ctx.Ctx.HTTPMethod, ctx.Ctx.RoutePath)
+	n, params, method := r.find(ctx.Ctx.HTTPMethod, ctx.Ctx.RoutePath)
 	if n != nil {
-		ctx.Ctx.Params = params
-		key := ctx.Ctx.HTTPMethod + "_" + n.pattern
-		r.handlers[key](ctx.Ctx)
+		if !ctx.Ctx.config.DisableTrailingSlashRedirect {
+			if fixed, ok := r.fixTrailingSlash(ctx.Ctx.RoutePath, n.pattern); ok {
+				serveRoot(ctx.Ctx, func(ctx *Context) { redirectPath(ctx, fixed) })
+				return
+			}
+		}
+		if method != ctx.Ctx.HTTPMethod {
+			ctx.Ctx.writer.discardBody = true
+		}
+		ctx.Ctx.params = params
+		ctx.Ctx.pattern = n.pattern
+		key := method + "_" + n.pattern
+		rt := r.routes[key]
+		ctx.Ctx.middleware = rt.chain()
+		ctx.Ctx.Next()
+	} else if allow := r.allowed(ctx.Ctx); len(allow) > 0 && ctx.Ctx.HTTPMethod == http.MethodOptions && !ctx.Ctx.config.DisableAutoOPTIONS {
+		ctx.Ctx.middleware = append(r.allowedGroup(allow, ctx.Ctx.RoutePath).chain(), func(ctx *Context) {
+			ctx.SetHeader("Allow", strings.Join(allow, ", "))
+			ctx.NoContent()
+		})
+		ctx.Ctx.Next()
+	} else if fixed, ok := r.fixCase(ctx.Ctx); ok {
+		serveRoot(ctx.Ctx, func(ctx *Context) { redirectPath(ctx, fixed) })
+	} else if len(allow) > 0 {
+		ctx.Ctx.ResponseWriter.Header().Set("Allow", strings.Join(allow, ", "))
+		handler := ctx.Ctx.engine.noMethod
+		if handler == nil {
+			handler = func(ctx *Context) {
+				http.Error(ctx.ResponseWriter, "METHOD NOT ALLOWED", http.StatusMethodNotAllowed)
+			}
+		}
+		serveRoot(ctx.Ctx, handler)
 	} else {
-		http.Error(ctx.Ctx.ResponseWriter, "NOT FOUND", http.StatusNotFound)
+		handler := ctx.Ctx.engine.noRoute
+		if handler == nil {
+			handler = func(ctx *Context) {
+				http.Error(ctx.ResponseWriter, "NOT FOUND", http.StatusNotFound)
+			}
+		}
+		serveRoot(ctx.Ctx, handler)
 	}
 }
 
-// joinParts joins a slice of parts into a single string with '/' separator.
-func joinParts(parts []string) string {
-	length := 0
-	for _, part := range parts {
-		length += len(part) + 1
+// serveRoot runs the root group middleware followed by handler, so the redirects, 404s and
+// 405s the router answers itself are logged, measured and recovered like routed requests.
+func serveRoot(ctx *Context, handler HandlerFunc) {
+	ctx.middleware = append(ctx.engine.RouterGroup.chain(), handler)
+	ctx.Next()
+}
+
+// fixTrailingSlash returns path rebuilt from pattern with its trailing slash added or
+// removed to match, and whether the slash differed. Catch-all patterns keep the path as it is.
+func (r *router) fixTrailingSlash(path, pattern string) (string, bool) {
+	if path == "/" {
+		return "", false
 	}
-	joined := make([]byte, length-1)
+	parts := parsePattern(pattern, r.syntax.wildcard)
+	if len(parts) > 0 && r.syntax.isCatchAll(parts[len(parts)-1]) {
+		return "", false
+	}
+
+	if strings.HasSuffix(path, "/") == strings.HasSuffix(pattern, "/") {
+		return "", false
+	}
+	return r.canonicalPath(pattern, path), true
+}
+
+// fixCase looks the request path up with static parts compared case-insensitively and
+// returns it with the registered casing when Config.RedirectFixedPath is enabled.
+// Parameter values keep the casing of the request.
+func (r *router) fixCase(ctx *Context) (string, bool) {
+	if !ctx.config.RedirectFixedPath {
+		return "", false
+	}
+
+	method, path := ctx.HTTPMethod, ctx.RoutePath
+	root, ok := r.root[method]
+	if !ok && method == http.MethodHead {
+		root, ok = r.root[http.MethodGet]
+	}
+	if !ok {
+		return "", false
+	}
+
+	n := root.search(parsePattern(path, 0), 0, true)
+	if n == nil {
+		return "", false
+	}
+
+	fixed := r.canonicalPath(n.pattern, path)
+	return fixed, fixed != path
+}
+
+// canonicalPath rebuilds the request path matched by pattern as a single slash before
+// each segment, taking static segments from the pattern and parameter values from path,
+// and ending in a slash only when pattern does. Rebuilding it, rather than editing path,
+// keeps empty segments such as the one in //host from turning the result into a
+// protocol-relative URL.
+func (r *router) canonicalPath(pattern, path string) string {
+	searchParts := parsePattern(path, 0)
+	var b strings.Builder
+	for i, part := range parsePattern(pattern, r.syntax.wildcard) {
+		b.WriteByte('/')
+		if r.syntax.isCatchAll(part) {
+			b.WriteString(remainingPath(path, i))
+			break
+		} else if r.syntax.isParam(part) {
+			b.WriteString(searchParts[i])
+		} else {
+			b.WriteString(part)
+		}
+	}
+	if strings.HasSuffix(pattern, "/") && !strings.HasSuffix(b.String(), "/") {
+		b.WriteByte('/')
+	}
+
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+// redirectPath redirects the request to path, keeping its query string. GET and HEAD
+// requests get a 301; other methods get a 308 so the body is sent again. Leading slashes
+// are collapsed so the target always stays on this host.
+func redirectPath(ctx *Context, path string) {
+	path = "/" + strings.TrimLeft(path, "/")
+	code := http.StatusPermanentRedirect
+	if ctx.HTTPMethod == http.MethodGet || ctx.HTTPMethod == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	if ctx.Request.URL.RawQuery != "" {
+		path += "?" + ctx.Request.URL.RawQuery
+	}
+	ctx.Redirect(code, path)
+}
+
+// allowed returns the sorted methods that have a route matching the request path,
+// including OPTIONS unless Config.DisableAutoOPTIONS is set.
+func (r *router) allowed(ctx *Context) []string {
+	var methods []string
+	for method := range r.root {
+		if n, _ := r.getRoute(method, ctx.RoutePath); n != nil {
+			methods = append(methods, method)
+		}
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+	if !slices.Contains(methods, http.MethodHead) && slices.Contains(methods, http.MethodGet) {
+		methods = append(methods, http.MethodHead)
+	}
+	if !slices.Contains(methods, http.MethodOptions) && !ctx.config.DisableAutoOPTIONS {
+		methods = append(methods, http.MethodOptions)
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+// allowedGroup returns the group owning the first route, in methods order, that matches
+// path, so an automatic OPTIONS response runs the middleware of the routes it describes.
+func (r *router) allowedGroup(methods []string, path string) *RouterGroup {
+	for _, method := range methods {
+		if n, _ := r.getRoute(method, path); n != nil {
+			return r.routes[method+"_"+n.pattern].group
+		}
+	}
+	return nil
+}
+
+// remainingPath returns what follows the first index segments of path, minus the single
+// separating slash. Any further slashes are kept exactly as they appear in the request.
+func remainingPath(path string, index int) string {
 	pos := 0
-	for _, part := range parts {
-		copy(joined[pos:], part)
-		pos += len(part)
-		if pos < length-1 {
-			joined[pos] = '/'
+	for seg := 0; seg < index; seg++ {
+		for pos < len(path) && path[pos] == '/' {
+			pos++
+		}
+		for pos < len(path) && path[pos] != '/' {
 			pos++
 		}
 	}
-	return string(joined)
+	if pos < len(path) && path[pos] == '/' {
+		pos++
+	}
+	return path[pos:]
 }
@@ -0,0 +1,250 @@
+package restrum
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// perform serves a request for method and target through e and returns the recorded
+// response. header holds alternating header names and values.
+func perform(e *Engine, method, target string, body io.Reader, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, body)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestTrailingSlashRedirectStaysOnHost(t *testing.T) {
+	e := New()
+	e.GET("/:name", func(ctx *Context) { ctx.String(http.StatusOK, ctx.Param("name")) })
+
+	for _, target := range []string{"//evil.example/", "///evil.example/"} {
+		w := perform(e, http.MethodGet, target, nil)
+		if w.Code != http.StatusMovedPermanently {
+			t.Fatalf("%s: status = %d, want %d", target, w.Code, http.StatusMovedPermanently)
+		}
+		if loc := w.Header().Get("Location"); loc != "/evil.example" {
+			t.Errorf("%s: Location = %q, want %q", target, loc, "/evil.example")
+		}
+	}
+}
+
+func TestTrailingSlashRedirect(t *testing.T) {
+	e := New()
+	e.GET("/users/:id", func(ctx *Context) {})
+	e.GET("/docs/", func(ctx *Context) {})
+
+	tests := []struct {
+		target, location string
+	}{
+		{"/users/7/?x=1", "/users/7?x=1"},
+		{"/docs", "/docs/"},
+	}
+	for _, tt := range tests {
+		w := perform(e, http.MethodGet, tt.target, nil)
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != tt.location {
+			t.Errorf("%s: got %d %q, want 301 %q", tt.target, w.Code, w.Header().Get("Location"), tt.location)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	e := New()
+	e.GET("/items/:id", func(ctx *Context) {})
+	e.PUT("/items/:id", func(ctx *Context) {})
+
+	w := perform(e, http.MethodDelete, "/items/1", nil)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+	if allow, want := w.Header().Get("Allow"), "GET, HEAD, OPTIONS, PUT"; allow != want {
+		t.Errorf("Allow = %q, want %q", allow, want)
+	}
+
+	w = perform(e, http.MethodDelete, "/missing", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unrouted path: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if allow := w.Header().Get("Allow"); allow != "" {
+		t.Errorf("unrouted path: Allow = %q, want none", allow)
+	}
+}
+
+func TestStaticRouteWinsOverParam(t *testing.T) {
+	for _, staticFirst := range []bool{true, false} {
+		e := New()
+		static := func() { e.GET("/user/new", func(ctx *Context) { ctx.String(http.StatusOK, "new") }) }
+		param := func() { e.GET("/user/:id", func(ctx *Context) { ctx.String(http.StatusOK, "id="+ctx.Param("id")) }) }
+		if staticFirst {
+			static()
+			param()
+		} else {
+			param()
+			static()
+		}
+
+		if body := perform(e, http.MethodGet, "/user/new", nil).Body.String(); body != "new" {
+			t.Errorf("static first %v: /user/new got %q, want %q", staticFirst, body, "new")
+		}
+		if body := perform(e, http.MethodGet, "/user/42", nil).Body.String(); body != "id=42" {
+			t.Errorf("static first %v: /user/42 got %q, want %q", staticFirst, body, "id=42")
+		}
+	}
+}
+
+func TestStaticRouteBacktracksToParam(t *testing.T) {
+	e := New()
+	e.GET("/user/new", func(ctx *Context) { ctx.String(http.StatusOK, "new") })
+	e.GET("/user/:id/edit", func(ctx *Context) { ctx.String(http.StatusOK, "edit "+ctx.Param("id")) })
+
+	w := perform(e, http.MethodGet, "/user/new/edit", nil)
+	if w.Code != http.StatusOK || w.Body.String() != "edit new" {
+		t.Errorf("got %d %q, want 200 %q", w.Code, w.Body.String(), "edit new")
+	}
+}
+
+func TestCatchAllParam(t *testing.T) {
+	e := New()
+	e.GET("/static/*filepath", func(ctx *Context) { ctx.String(http.StatusOK, ctx.Param("filepath")) })
+	e.GET("/any/*", func(ctx *Context) { ctx.String(http.StatusOK, ctx.Param("*")) })
+
+	tests := []struct {
+		target, want string
+	}{
+		{"/static/css/app.css", "css/app.css"},
+		{"/any/a/b/c", "a/b/c"},
+	}
+	for _, tt := range tests {
+		w := perform(e, http.MethodGet, tt.target, nil)
+		if w.Code != http.StatusOK || w.Body.String() != tt.want {
+			t.Errorf("%s: got %d %q, want 200 %q", tt.target, w.Code, w.Body.String(), tt.want)
+		}
+	}
+}
+
+func TestRedirectFixedPath(t *testing.T) {
+	e := New(Config{RedirectFixedPath: true})
+	e.GET("/api/users/:name", func(ctx *Context) {})
+
+	w := perform(e, http.MethodGet, "/API/Users/Bob", nil)
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusMovedPermanently)
+	}
+	if loc := w.Header().Get("Location"); loc != "/api/users/Bob" {
+		t.Errorf("Location = %q, want %q", loc, "/api/users/Bob")
+	}
+}
+
+func TestAutomaticOptionsRunsRouteGroupMiddleware(t *testing.T) {
+	e := New()
+	api := e.Group("/api")
+	api.Use(CORSMiddleware(&Config{AllowOrigins: []string{"https://app.example"}, AllowMethods: []string{"GET"}}))
+	api.GET("/items", func(ctx *Context) {})
+
+	w := perform(e, http.MethodOptions, "/api/items", nil,
+		"Origin", "https://app.example", "Access-Control-Request-Method", "GET")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, "https://app.example")
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET" {
+		t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, "GET")
+	}
+	if w.Code >= http.StatusBadRequest {
+		t.Errorf("status = %d, want success", w.Code)
+	}
+}
+
+func TestAutomaticOptionsWithCallerConfig(t *testing.T) {
+	e := New(Config{AllowOrigins: []string{"https://app.example"}})
+	e.GET("/items", func(ctx *Context) {})
+
+	w := perform(e, http.MethodOptions, "/items", nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if allow, want := w.Header().Get("Allow"), "GET, HEAD, OPTIONS"; allow != want {
+		t.Errorf("Allow = %q, want %q", allow, want)
+	}
+
+	e = New(Config{DisableAutoOPTIONS: true})
+	e.GET("/items", func(ctx *Context) {})
+	if w := perform(e, http.MethodOptions, "/items", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("disabled: status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestTrailingSlashRedirectWithCallerConfig(t *testing.T) {
+	e := New(Config{AllowOrigins: []string{"https://app.example"}})
+	e.GET("/u", func(ctx *Context) {})
+	if w := perform(e, http.MethodGet, "/u/", nil); w.Code != http.StatusMovedPermanently {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusMovedPermanently)
+	}
+
+	e = New(Config{DisableTrailingSlashRedirect: true})
+	e.GET("/u", func(ctx *Context) {})
+	if w := perform(e, http.MethodGet, "/u/", nil); w.Code != http.StatusOK {
+		t.Errorf("disabled: status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestHeadFallsBackToGet(t *testing.T) {
+	var log bytes.Buffer
+	var written bool
+	e := New()
+	e.Use(LoggerWithConfig(LoggerConfig{Output: &log, Format: "{method} {path} {status}"}))
+	e.GET("/x", func(ctx *Context) {
+		ctx.String(http.StatusOK, "body")
+		written = ctx.Written()
+	})
+
+	w := perform(e, http.MethodHead, "/x", nil)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("got %d with %d body bytes, want 200 with none", w.Code, w.Body.Len())
+	}
+	if !written {
+		t.Error("Written() = false after the GET handler responded")
+	}
+	if !strings.HasSuffix(log.String(), "HEAD /x 200\n") {
+		t.Errorf("log = %q, want it to end in %q", log.String(), "HEAD /x 200\n")
+	}
+}
+
+func TestHostRouteWrongMethod(t *testing.T) {
+	e := New()
+	e.Host("api.example").POST("/h", func(ctx *Context) {})
+
+	serve := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "http://api.example/h", nil)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+	if allow, want := w.Header().Get("Allow"), "OPTIONS, POST"; allow != want {
+		t.Errorf("Allow = %q, want %q", allow, want)
+	}
+
+	if w := serve(http.MethodOptions); w.Code != http.StatusNoContent || w.Header().Get("Allow") != "OPTIONS, POST" {
+		t.Errorf("OPTIONS: got %d with Allow %q, want 204 with %q", w.Code, w.Header().Get("Allow"), "OPTIONS, POST")
+	}
+}
+
+func TestCatchAllMustBeLast(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering /f/{p...}/edit did not panic")
+		}
+	}()
+	New().GET("/f/{p...}/edit", func(ctx *Context) {})
+}
@@ -0,0 +1,62 @@
+package restrum
+
+import (
+	"net/http"
+	"os"
+	"path"
+)
+
+// Static serves the files under the root directory at relativePath within the group.
+// Missing files get a 404, and paths are cleaned so requests cannot escape root.
+// Directories are never listed: one without an index.html gets a 404 as well.
+func (e *RouterGroup) Static(relativePath, root string) {
+	fileServer := http.FileServer(noListingFS{http.Dir(root)})
+	handler := func(ctx *Context) {
+		req := new(http.Request)
+		*req = *ctx.Request
+		u := *ctx.Request.URL
+		u.Path = "/" + ctx.Param("filepath")
+		u.RawPath = ""
+		req.URL = &u
+
+		fileServer.ServeHTTP(ctx.ResponseWriter, req)
+	}
+
+	e.GET(relativePath, handler)
+	e.GET(path.Join(relativePath, "{filepath...}"), handler)
+}
+
+// StaticFile serves a single file at relativePath within the group.
+func (e *RouterGroup) StaticFile(relativePath, filepath string) {
+	e.GET(relativePath, func(ctx *Context) {
+		http.ServeFile(ctx.ResponseWriter, ctx.Request, filepath)
+	})
+}
+
+// noListingFS hides the directories of fs that have no index.html, so http.FileServer
+// answers them with a 404 instead of a listing.
+type noListingFS struct {
+	fs http.FileSystem
+}
+
+// Open opens name, reporting a directory without an index.html as missing.
+func (fs noListingFS) Open(name string) (http.File, error) {
+	f, err := fs.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		index, err := fs.fs.Open(path.Join(name, "index.html"))
+		if err != nil {
+			_ = f.Close()
+			return nil, os.ErrNotExist
+		}
+		_ = index.Close()
+	}
+	return f, nil
+}
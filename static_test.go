@@ -0,0 +1,49 @@
+package restrum
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStaticDoesNotListDirectories(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"a.txt":           "a",
+		"sub/b.txt":       "b",
+		"docs/index.html": "docs index",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := New()
+	e.Static("/s", root)
+
+	tests := []struct {
+		target string
+		code   int
+		body   string
+	}{
+		{"/s", http.StatusNotFound, ""},
+		{"/s/sub/", http.StatusNotFound, ""},
+		{"/s/a.txt", http.StatusOK, "a"},
+		{"/s/sub/b.txt", http.StatusOK, "b"},
+		{"/s/docs/", http.StatusOK, "docs index"},
+	}
+	for _, tt := range tests {
+		w := perform(e, http.MethodGet, tt.target, nil)
+		if w.Code != tt.code {
+			t.Errorf("%s: status = %d, want %d", tt.target, w.Code, tt.code)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.target, w.Body.String(), tt.body)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package restrum
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Timeout creates a middleware that gives the rest of the chain d to finish. The request
+// context is cancelled after d and, if the handler has not returned by then, a 503 is
+// sent and its later writes fail with http.ErrHandlerTimeout. A handler that ignores
+// ctx.Request.Context() keeps running in the background after the timeout, so handlers
+// should watch the context to actually abort their work; functions it registers with
+// Context.Defer run when it eventually returns. The response is buffered until the
+// handler returns, so streaming responses do not reach the client early.
+//
+// When Timeouts are nested, the innermost one wins: it replaces the deadline of the
+// enclosing Timeout, whether d is longer or shorter, instead of running under it.
+func Timeout(d time.Duration) HandlerFunc {
+	return func(ctx *Context) {
+		parent := ctx.Request.Context()
+		if enclosing := ctx.timeout; enclosing != nil {
+			enclosing.overridden.Store(true)
+			parent = enclosing.parent
+		}
+		reqCtx, cancel := context.WithTimeout(parent, d)
+		defer cancel()
+		scope := &timeoutScope{parent: parent}
+
+		original := ctx.ResponseWriter
+		writer := &timeoutWriter{header: original.Header().Clone()}
+
+		// The handler runs on a copy of the context so it never shares state with this
+		// goroutine once the timeout has fired.
+		inner := *ctx
+		inner.Request = ctx.Request.WithContext(reqCtx)
+		inner.writer = &responseWriter{ResponseWriter: writer, ctx: &inner}
+		inner.ResponseWriter = inner.writer
+		inner.keys = maps.Clone(ctx.keys)
+		inner.deferred = nil
+		inner.timeout = scope
+		inner.phases = slices.Clip(ctx.phases)
+
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				recovered := recover()
+				writer.mu.Lock()
+				timedOut := writer.timedOut
+				writer.finished = !timedOut
+				writer.mu.Unlock()
+
+				if timedOut {
+					// Nobody is waiting for this copy any more, so its deferred
+					// functions have to run here.
+					inner.runDeferred()
+					return
+				}
+				if recovered != nil {
+					panicked <- recovered
+					return
+				}
+				close(done)
+			}()
+			inner.Next()
+		}()
+
+		select {
+		case recovered := <-panicked:
+			ctx.deferred = append(ctx.deferred, inner.deferred...)
+			panic(recovered)
+		case <-done:
+		case <-reqCtx.Done():
+			writer.mu.Lock()
+			if !writer.finished && !scope.overridden.Load() {
+				writer.timedOut = true
+				if reqCtx.Err() == context.DeadlineExceeded {
+					http.Error(original, "SERVICE UNAVAILABLE", http.StatusServiceUnavailable)
+				}
+				writer.mu.Unlock()
+				return
+			}
+			writer.mu.Unlock()
+
+			// The handler returned just as the deadline passed, or a nested Timeout now
+			// owns the deadline; either way its result still counts.
+			select {
+			case recovered := <-panicked:
+				ctx.deferred = append(ctx.deferred, inner.deferred...)
+				panic(recovered)
+			case <-done:
+			}
+		}
+
+		header := original.Header()
+		clear(header)
+		maps.Copy(header, writer.header)
+		if writer.code != 0 {
+			original.WriteHeader(writer.code)
+		}
+		original.Write(writer.buf.Bytes())
+
+		inner.ResponseWriter = original
+		inner.writer = ctx.writer
+		inner.deferred = append(ctx.deferred, inner.deferred...)
+		inner.timeout = ctx.timeout
+		*ctx = inner
+	}
+}
+
+// WithTimeout creates route-level middleware that gives a single route d to finish, e.g.
+// group.GET(pattern, WithTimeout(time.Minute), handler). It overrides any Timeout set on
+// the route's groups and otherwise behaves as Timeout.
+func WithTimeout(d time.Duration) HandlerFunc {
+	return Timeout(d)
+}
+
+// timeoutScope links a Timeout to those nested inside it.
+type timeoutScope struct {
+	// parent is the request context the Timeout derived its deadline from; nested
+	// Timeouts derive theirs from it too so they are not bound by the enclosing deadline.
+	parent context.Context
+	// overridden is set once a nested Timeout has taken over the deadline.
+	overridden atomic.Bool
+}
+
+// timeoutWriter buffers the response of a handler running under Timeout.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	code     int
+	timedOut bool
+	finished bool
+}
+
+// Header returns the buffered response header.
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader records the status code. Informational 1xx codes are dropped since the
+// response is only sent once the handler returns.
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code >= 100 && code < 200 {
+		return
+	}
+	if w.code == 0 && !w.timedOut {
+		w.code = code
+	}
+}
+
+// Write buffers data, or fails once the handler has timed out.
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.buf.Write(data)
+}
@@ -0,0 +1,94 @@
+package restrum
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTimeoutSlowHandler(t *testing.T) {
+	writeErr := make(chan error, 1)
+	deferred := make(chan struct{})
+
+	e := New()
+	e.GET("/slow", Timeout(20*time.Millisecond), func(ctx *Context) {
+		ctx.Defer(func() { close(deferred) })
+		<-ctx.Request.Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		_, err := ctx.ResponseWriter.Write([]byte("late"))
+		writeErr <- err
+	})
+
+	w := perform(e, http.MethodGet, "/slow", nil)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	select {
+	case err := <-writeErr:
+		if !errors.Is(err, http.ErrHandlerTimeout) {
+			t.Errorf("late write error = %v, want %v", err, http.ErrHandlerTimeout)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler did not finish")
+	}
+	select {
+	case <-deferred:
+	case <-time.After(time.Second):
+		t.Fatal("deferred function did not run after the timeout")
+	}
+}
+
+func TestTimeoutFastHandler(t *testing.T) {
+	ran := false
+	e := New()
+	e.GET("/fast", Timeout(time.Second), func(ctx *Context) {
+		ctx.Defer(func() { ran = true })
+		ctx.SetHeader("X-Handler", "1")
+		ctx.String(http.StatusCreated, "done")
+	})
+
+	w := perform(e, http.MethodGet, "/fast", nil)
+	if w.Code != http.StatusCreated || w.Body.String() != "done" || w.Header().Get("X-Handler") != "1" {
+		t.Errorf("got %d %q %v, want 201 \"done\" with X-Handler", w.Code, w.Body.String(), w.Header())
+	}
+	if !ran {
+		t.Error("deferred function did not run")
+	}
+}
+
+func TestRouteTimeoutOverridesGroup(t *testing.T) {
+	e := New()
+	g := e.Group("/g")
+	g.Use(Timeout(100 * time.Millisecond))
+	g.GET("/report", WithTimeout(time.Second), func(ctx *Context) {
+		select {
+		case <-time.After(150 * time.Millisecond):
+			ctx.String(http.StatusOK, "report")
+		case <-ctx.Request.Context().Done():
+		}
+	})
+	slow := func(ctx *Context) {
+		<-ctx.Request.Context().Done()
+		time.Sleep(10 * time.Millisecond)
+	}
+	g.GET("/quick", WithTimeout(20*time.Millisecond), slow)
+	g.GET("/default", slow)
+
+	if w := perform(e, http.MethodGet, "/g/report", nil); w.Code != http.StatusOK || w.Body.String() != "report" {
+		t.Errorf("longer route timeout: got %d %q, want 200 %q", w.Code, w.Body.String(), "report")
+	}
+
+	start := time.Now()
+	if w := perform(e, http.MethodGet, "/g/quick", nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("shorter route timeout: status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("shorter route timeout took %v, want under the group's 100ms", elapsed)
+	}
+
+	if w := perform(e, http.MethodGet, "/g/default", nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("group timeout: status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
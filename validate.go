@@ -0,0 +1,194 @@
+//go:build !restrum_nobind
+
+package restrum
+
+import (
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes a struct field that failed a validation rule.
+type FieldError struct {
+	// Field is the path of the field, e.g., Address.City.
+	Field string
+	// Rule is the failing rule, e.g., min.
+	Rule string
+	// Param is the rule's parameter, e.g., 3 in min=3, or empty.
+	Param string
+}
+
+// Error returns a description of the failure.
+func (e FieldError) Error() string {
+	if e.Param != "" {
+		return e.Field + " failed " + e.Rule + "=" + e.Param
+	}
+	return e.Field + " failed " + e.Rule
+}
+
+// ValidationErrors lists every field that failed validation. Handlers can answer it with
+// http.StatusBadRequest.
+type ValidationErrors []FieldError
+
+// Error returns the failures joined with semicolons.
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// BindAndValidate binds the request body like Bind, then validates obj like Validate.
+func (ctx *Context) BindAndValidate(obj interface{}) error {
+	if err := ctx.Bind(obj); err != nil {
+		return err
+	}
+	return Validate(obj)
+}
+
+// Validate checks the fields of the struct obj points to against their `validate` tags,
+// descending into nested structs, and returns ValidationErrors listing each failure.
+// Rules are comma-separated: required, min=N, max=N, len=N, email, and omitempty, which
+// skips the other rules for a zero value. min, max and len compare the length of strings,
+// slices and maps and the value of numbers. It panics on an unknown rule.
+func Validate(obj interface{}) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errBindTarget
+	}
+
+	var errs ValidationErrors
+	validateStruct(rv.Elem(), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateStruct validates the fields of v, naming them under prefix.
+func validateStruct(v reflect.Value, prefix string, errs *ValidationErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		name := prefix + field.Name
+		if tag != "" {
+			validateField(fv, name, tag, errs)
+		}
+
+		if fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			if field.Anonymous {
+				validateStruct(fv, prefix, errs)
+			} else {
+				validateStruct(fv, name+".", errs)
+			}
+		}
+	}
+}
+
+// validateField checks fv against the rules in tag.
+func validateField(fv reflect.Value, name, tag string, errs *ValidationErrors) {
+	rules := strings.Split(tag, ",")
+	if slices.Contains(rules, "omitempty") && fv.IsZero() {
+		return
+	}
+
+	for _, rule := range rules {
+		rule, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		var ok bool
+		switch rule {
+		case "", "omitempty":
+			continue
+		case "required":
+			ok = !isEmpty(fv)
+		case "min", "max", "len":
+			ok = checkSize(fv, rule, param)
+		case "email":
+			ok = isEmail(fv)
+		default:
+			panic("restrum: unknown validation rule " + rule + " on " + name)
+		}
+		if !ok {
+			*errs = append(*errs, FieldError{Field: name, Rule: rule, Param: param})
+		}
+	}
+}
+
+// isEmpty reports whether v is a zero value, an empty string, slice or map, or a nil pointer.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// checkSize compares the length or numeric value of v with the rule's parameter.
+func checkSize(v reflect.Value, rule, param string) bool {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic("restrum: invalid parameter for validation rule " + rule + ": " + param)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+
+	var size float64
+	switch v.Kind() {
+	case reflect.String:
+		size = float64(utf8.RuneCountInString(v.String()))
+	case reflect.Slice, reflect.Map, reflect.Array:
+		size = float64(v.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = v.Float()
+	default:
+		return false
+	}
+
+	switch rule {
+	case "min":
+		return size >= limit
+	case "max":
+		return size <= limit
+	default:
+		return size == limit
+	}
+}
+
+// isEmail reports whether v is a string holding a bare email address.
+func isEmail(v reflect.Value) bool {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return false
+	}
+	addr, err := mail.ParseAddress(v.String())
+	return err == nil && addr.Address == v.String()
+}
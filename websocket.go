@@ -0,0 +1,80 @@
+package restrum
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// websocketGUID is the fixed GUID from RFC 6455 used to compute Sec-WebSocket-Accept.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	// ErrBadHandshake is returned by Upgrade when the request is not a valid WebSocket handshake.
+	ErrBadHandshake = errors.New("websocket: bad handshake")
+	// ErrHijackUnsupported is returned by Upgrade when the response writer cannot be hijacked.
+	ErrHijackUnsupported = errors.New("websocket: response writer does not support hijacking")
+)
+
+// Upgrade performs the WebSocket opening handshake and hijacks the connection.
+// The returned connection speaks raw WebSocket frames; framing is left to the caller.
+// On a bad handshake a 400 response is sent and ErrBadHandshake is returned.
+func (ctx *Context) Upgrade() (net.Conn, *bufio.ReadWriter, error) {
+	req := ctx.Request
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if req.Method != http.MethodGet ||
+		!headerHasToken(req.Header, "Connection", "upgrade") ||
+		!headerHasToken(req.Header, "Upgrade", "websocket") ||
+		req.Header.Get("Sec-WebSocket-Version") != "13" ||
+		key == "" {
+		http.Error(ctx.ResponseWriter, "bad websocket handshake", http.StatusBadRequest)
+		return nil, nil, ErrBadHandshake
+	}
+
+	hijacker, ok := ctx.ResponseWriter.(http.Hijacker)
+	if !ok {
+		http.Error(ctx.ResponseWriter, "websocket not supported", http.StatusInternalServerError)
+		return nil, nil, ErrHijackUnsupported
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx.ResponseCode = http.StatusSwitchingProtocols
+	_, err = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n\r\n")
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	return conn, rw, nil
+}
+
+// websocketAccept computes the Sec-WebSocket-Accept value for the client's key.
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerHasToken reports whether the comma-separated header contains token, ignoring case.
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package restrum
+
+import (
+	"net/http"
+	"path"
+)
+
+// mountMethods lists the methods a handler mounted with Mount is registered for.
+var mountMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace,
+}
+
+// Mount serves every request under prefix within the group with handler, under all methods.
+// The handler sees the request path with the group prefix and prefix stripped, so
+// http.Handler code written for the root of a server works unchanged.
+func (e *RouterGroup) Mount(prefix string, handler http.Handler) {
+	serve := func(ctx *Context) {
+		req := new(http.Request)
+		*req = *ctx.Request
+		u := *ctx.Request.URL
+		u.Path = "/" + ctx.Param("path")
+		u.RawPath = ""
+		req.URL = &u
+
+		handler.ServeHTTP(ctx.ResponseWriter, req)
+	}
+
+	for _, method := range mountMethods {
+		e.AddRoutes(method, prefix, serve)
+		e.AddRoutes(method, path.Join(prefix, "{path...}"), serve)
+	}
+}
+
+// WrapH adapts an http.Handler to a HandlerFunc.
+func WrapH(h http.Handler) HandlerFunc {
+	return func(ctx *Context) {
+		h.ServeHTTP(ctx.ResponseWriter, ctx.Request)
+	}
+}
+
+// WrapF adapts an http.HandlerFunc to a HandlerFunc.
+func WrapF(f http.HandlerFunc) HandlerFunc {
+	return WrapH(f)
+}
+
+// WrapMiddleware adapts a standard func(http.Handler) http.Handler middleware to a
+// HandlerFunc. The rest of the chain runs as the wrapped handler, with whatever writer and
+// request the middleware passes on; the chain stops if the middleware does not call it.
+func WrapMiddleware(m func(http.Handler) http.Handler) HandlerFunc {
+	return func(ctx *Context) {
+		writer, req := ctx.ResponseWriter, ctx.Request
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx.ResponseWriter, ctx.Request = w, r
+			ctx.Next()
+		})
+		m(next).ServeHTTP(writer, req)
+		ctx.ResponseWriter, ctx.Request = writer, req
+	}
+}